@@ -0,0 +1,477 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ajtroup1/DocMate/internal/cache"
+	"github.com/ajtroup1/DocMate/internal/coverage"
+	"github.com/ajtroup1/DocMate/internal/generator"
+	"github.com/ajtroup1/DocMate/internal/lexer"
+	"github.com/ajtroup1/DocMate/internal/lint"
+	"github.com/ajtroup1/DocMate/internal/parser"
+	"github.com/ajtroup1/DocMate/internal/schema"
+	"github.com/ajtroup1/DocMate/internal/snapshot"
+	"github.com/ajtroup1/DocMate/internal/types"
+	"github.com/ajtroup1/DocMate/internal/utils"
+)
+
+// Writes the default settings, with any setting given as a flag or environment variable filled in
+func runInit(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("init", "", opts)
+	force := flags.Bool("force", false, "overwrite an existing settings file")
+	format := flags.String("format", utils.FormatJSON, "settings file format, `json`, `yaml` or `toml`")
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+
+	// -settings names the file, and its extension decides the format
+	path := opts.settingsPath
+	if path == "" {
+		name, err := utils.SettingsFileName(*format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, Red+err.Error()+Clear)
+			return exitUsage
+		}
+		path = name
+	}
+	if _, err := os.Stat(path); err == nil && !*force {
+		return fail("Settings file %s already exists, pass -force to overwrite it", path)
+	}
+
+	// The existing file is left out so -force starts over from the defaults
+	config, err := utils.LoadConfig("", opts.overrides)
+	if err != nil {
+		return fail("Error reading settings: %v", err)
+	}
+	if err := utils.WriteSettings(path, config.Settings); err != nil {
+		return fail("Error writing settings: %v", err)
+	}
+
+	opts.printf(Green+"Settings written to %s\n"+Clear, path)
+	return exitOK
+}
+
+func runGenerate(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("generate", "[save data]", opts)
+	format := flags.String("format", "markdown", "output format, `markdown` or `html`")
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+	if *format != "markdown" && *format != "md" && *format != "html" {
+		fmt.Fprintf(os.Stderr, Red+"Unknown format `%s`, expected `markdown` or `html`"+Clear+"\n", *format)
+		return exitUsage
+	}
+
+	// `docmate generate <save data>` generates from a snapshot instead of lexing the project,
+	// so it doesn't need a settings file
+	if flags.NArg() > 0 {
+		config, err := opts.loadConfig()
+		if err != nil {
+			return fail("Error reading settings: %v", err)
+		}
+
+		snap, err := snapshot.Load(flags.Arg(0))
+		if err != nil {
+			return fail("Error loading save data: %v", err)
+		}
+		return generate(opts, snap.Packages, snap.Settings(config.Settings), *format)
+	}
+
+	settings, code := settingsOrFail(opts)
+	if settings == nil {
+		return code
+	}
+	parser, diagnostics, err := parseProject(ctx, opts, settings)
+	if err != nil {
+		return fail("Error extracting comments: %v", err)
+	}
+	printDiagnostics(opts, diagnostics)
+
+	// The documentation is still written, but errors fail the run the same way lint does
+	if code := generate(opts, parser.Packages, settings, *format); code != exitOK {
+		return code
+	}
+	if errorCount(diagnostics) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func runSave(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("save", "", opts)
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+
+	settings, code := settingsOrFail(opts)
+	if settings == nil {
+		return code
+	}
+	parser, diagnostics, err := parseProject(ctx, opts, settings)
+	if err != nil {
+		return fail("Error extracting comments: %v", err)
+	}
+	printDiagnostics(opts, diagnostics)
+
+	// Saved either way, like generate
+	outputPath, err := snapshot.Save(parser.Packages, settings)
+	if err != nil {
+		return fail("Error saving documentation data: %v", err)
+	}
+	opts.printf(Green+"Save data written to %s\n"+Clear, outputPath)
+	if errorCount(diagnostics) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// Also reports documentation that no longer matches the code
+func runLint(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("lint", "", opts)
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+
+	settings, code := settingsOrFail(opts)
+	if settings == nil {
+		return code
+	}
+	parser, diagnostics, _, err := parseProjectFiles(ctx, opts, settings, true)
+	if err != nil {
+		return fail("Error extracting comments: %v", err)
+	}
+	diagnostics = append(diagnostics, lint.Check(parser.Bindings)...)
+	printDiagnostics(opts, diagnostics)
+
+	if errorCount(diagnostics) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// Runs every check without writing any output, so it can gate merges
+func runCheck(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("check", "", opts)
+	min := flags.Float64("min", 0, "minimum coverage percentage required")
+	strict := flags.Bool("strict", false, "fail on warnings as well as errors")
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+
+	settings, code := settingsOrFail(opts)
+	if settings == nil {
+		return code
+	}
+	parser, diagnostics, files, err := parseProjectFiles(ctx, opts, settings, true)
+	if err != nil {
+		return fail("Error extracting comments: %v", err)
+	}
+	diagnostics = append(diagnostics, lint.Check(parser.Bindings)...)
+	report := coverage.Build(files, parser.Bindings)
+	diagnostics = append(diagnostics, unreported(diagnostics, report.Diagnostics)...)
+	printDiagnostics(opts, diagnostics)
+
+	errs := errorCount(diagnostics)
+	warnings := len(diagnostics) - errs
+	failed := errs > 0 || (*strict && warnings > 0) || report.Percent() < *min
+
+	opts.printf("%d error(s), %d warning(s), documentation coverage %.1f%% (%d/%d)\n",
+		errs, warnings, report.Percent(), report.Covered, report.Total)
+	if report.Percent() < *min {
+		fmt.Printf(Red+"Coverage is below the minimum of %.1f%%\n"+Clear, *min)
+	}
+	if failed {
+		fmt.Println(Red + "Check failed" + Clear)
+		return exitFailure
+	}
+	opts.printf(Green + "Check passed\n" + Clear)
+	return exitOK
+}
+
+// Prints the reference of every header and tag. It describes DocMate rather than the project,
+// so no settings are read and the default template is always used
+func runTags(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("tags", "", opts)
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+
+	content, err := generator.New(nil, &types.Settings{}).RenderReference()
+	if err != nil {
+		return fail("Error rendering the tag reference: %v", err)
+	}
+	os.Stdout.Write(content)
+	return exitOK
+}
+
+// Reports how many exported identifiers are documented, failing when below `-min`
+func runCoverage(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("coverage", "", opts)
+	min := flags.Float64("min", 0, "minimum coverage percentage required")
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+
+	settings, code := settingsOrFail(opts)
+	if settings == nil {
+		return code
+	}
+	parser, _, files, err := parseProjectFiles(ctx, opts, settings, true)
+	if err != nil {
+		return fail("Error extracting comments: %v", err)
+	}
+	report := coverage.Build(files, parser.Bindings)
+	printDiagnostics(opts, report.Diagnostics)
+
+	for _, pkg := range report.Packages {
+		opts.printf("%-40s %6.1f%% (%d/%d)\n", pkg.Name, pkg.Percent(), pkg.Covered, pkg.Total)
+		for _, file := range pkg.Files {
+			opts.printf("  %-38s %6.1f%% (%d/%d)\n", file.Path, file.Percent(), file.Covered, file.Total)
+			for _, gap := range file.Gaps {
+				opts.printf(Yellow+"    %s: %s %s is undocumented\n"+Clear, gap.Pos, gap.Kind, gap.Name)
+			}
+		}
+	}
+
+	color := Green
+	if report.Percent() < *min {
+		color = Red
+	}
+	fmt.Printf(color+"Documentation coverage: %.1f%% (%d/%d)\n"+Clear, report.Percent(), report.Covered, report.Total)
+
+	if report.Percent() < *min {
+		fmt.Printf(Red+"Coverage is below the minimum of %.1f%%\n"+Clear, *min)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// Returns the coverage diagnostics for files the resolver hasn't already reported as unparseable
+func unreported(diagnostics, skipped []types.Diagnostic) []types.Diagnostic {
+	reported := make(map[string]bool)
+	for _, d := range diagnostics {
+		if d.Code == types.CodeSourceUnavailable {
+			reported[d.Pos.Filepath] = true
+		}
+	}
+
+	var extra []types.Diagnostic
+	for _, d := range skipped {
+		if !reported[d.Pos.Filepath] {
+			extra = append(extra, d)
+		}
+	}
+	return extra
+}
+
+// Loads and validates the settings, pointing at `docmate init` when there is no settings file
+// and the project wasn't given as a flag or environment variable either
+func settingsOrFail(opts *options) (*types.Settings, int) {
+	config, code := configOrFail(opts)
+	if config == nil {
+		return nil, code
+	}
+	return config.Settings, exitOK
+}
+
+// Same as settingsOrFail, also returning where the settings came from
+func configOrFail(opts *options) (*utils.Config, int) {
+	config, err := opts.loadConfig()
+	if err != nil {
+		return nil, fail("Error reading settings: %v", err)
+	}
+	if config.Path == "" && config.Sources["Project_Path"] == utils.SourceDefault {
+		if opts.settingsPath != "" {
+			return nil, fail("Settings file %s not found, run `docmate init` to create one", opts.settingsPath)
+		}
+		return nil, fail("No settings file found up to the go.mod root, run `docmate init` to create one")
+	}
+
+	if errs := config.Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, Red+err.Error()+Clear)
+		}
+		return nil, fail("Invalid settings")
+	}
+	return config, exitOK
+}
+
+// Prints the merged settings and where each value came from
+func runConfig(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("config show", "", opts)
+	if len(args) == 0 || args[0] != "show" {
+		flags.Usage()
+		return exitUsage
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return parseError(err)
+	}
+
+	config, err := opts.loadConfig()
+	if err != nil {
+		return fail("Error reading settings: %v", err)
+	}
+
+	switch {
+	case config.Path != "":
+		fmt.Printf("Settings file: %s\n", config.Path)
+	case opts.settingsPath != "":
+		fmt.Printf("Settings file: %s (not found)\n", opts.settingsPath)
+	default:
+		fmt.Println("Settings file: none found")
+	}
+	for _, field := range utils.Fields {
+		fmt.Printf("%-20s %-40s %s\n", field.Key, field.Display(config.Settings), config.Origin(field))
+	}
+
+	// Problems are listed but don't fail the command, showing the settings is what it's for
+	for _, err := range config.Validate() {
+		fmt.Println(Yellow + err.Error() + Clear)
+	}
+	return exitOK
+}
+
+// Parses the project for generation, where every unchanged file can come from the cache
+func parseProject(ctx context.Context, opts *options, settings *types.Settings) (*parser.Parser, []types.Diagnostic, error) {
+	parser, diagnostics, _, err := parseProjectFiles(ctx, opts, settings, false)
+	return parser, diagnostics, err
+}
+
+// Also returns every Go file that was read, for coverage. Binding every block to its
+// declaration needs the Go source, so only lexing is cached when bindings are needed
+func parseProjectFiles(ctx context.Context, opts *options, settings *types.Settings, bindings bool) (*parser.Parser, []types.Diagnostic, []string, error) {
+	lexer := projectLexer(opts, settings)
+	c := openCache(opts, settings)
+	if c != nil {
+		lexer.Cache = c
+	}
+
+	files, err := lexer.Files()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	comments, err := lexer.ExtractCommentsFrom(ctx, files)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	parser := parser.New(comments, settings.CapitalizeItems)
+	// Invalid custom tags were reported when the settings were validated
+	parser.Headers, _ = schema.Extend(settings.CustomTags)
+	if c != nil && !bindings {
+		parser.Cache = c
+	}
+	parser.ParseComments()
+	opts.logf("%d comment block(s) found in %d file(s)\n", len(comments), len(files))
+
+	if c != nil {
+		if err := c.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, Yellow+err.Error()+Clear)
+		}
+	}
+
+	return parser, append(lexer.Diagnostics, parser.Diagnostics...), files, nil
+}
+
+func projectLexer(opts *options, settings *types.Settings) *lexer.Lexer {
+	lexer := lexer.New(settings.IncludeTests, settings.ProjectPath)
+	lexer.Verbose = opts.verbose && !opts.quiet
+	lexer.Include = settings.Include
+	lexer.Exclude = settings.Exclude
+	return lexer
+}
+
+// Opens the project's cache unless -no-cache is set. A cache that can't be opened only costs
+// speed, so it's reported and skipped
+func openCache(opts *options, settings *types.Settings) *cache.Cache {
+	if opts.noCache {
+		return nil
+	}
+	// Custom tags change what blocks parse to, so they're part of the key
+	customTags, _ := json.Marshal(settings.CustomTags)
+	c, err := cache.Open(settings.ProjectPath, fmt.Sprintf("capitalize=%t custom_tags=%s", settings.CapitalizeItems, customTags))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, Yellow+err.Error()+Clear)
+		return nil
+	}
+	return c
+}
+
+// Removes the cache of the project, or of every project with -all
+func runCache(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("cache clean", "", opts)
+	all := flags.Bool("all", false, "remove the cache of every project")
+	if len(args) == 0 || args[0] != "clean" {
+		flags.Usage()
+		return exitUsage
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return parseError(err)
+	}
+
+	config, err := opts.loadConfig()
+	if err != nil {
+		return fail("Error reading settings: %v", err)
+	}
+	path, err := cache.Clean(config.Settings.ProjectPath, *all)
+	if err != nil {
+		return fail("Error cleaning cache: %v", err)
+	}
+	opts.printf(Green+"Removed %s\n"+Clear, path)
+	return exitOK
+}
+
+// Prints the diagnostics sorted by position, -q leaves out warnings
+func printDiagnostics(opts *options, diagnostics []types.Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Pos, diagnostics[j].Pos
+		if a.Filepath != b.Filepath {
+			return a.Filepath < b.Filepath
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	for _, diagnostic := range diagnostics {
+		color := Yellow
+		if diagnostic.Severity == types.SeverityError {
+			color = Red
+		} else if opts.quiet {
+			continue
+		}
+		fmt.Println(color + diagnostic.String() + Clear)
+	}
+}
+
+func errorCount(diagnostics []types.Diagnostic) int {
+	count := 0
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == types.SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+func generate(opts *options, pkgs []types.Package, settings *types.Settings, format string) int {
+	generator := generator.New(pkgs, settings)
+
+	var outputPath string
+	var err error
+	if format == "html" {
+		outputPath, err = generator.GenerateHTML()
+	} else {
+		outputPath, err = generator.GenerateMarkdown()
+	}
+	if err != nil {
+		return fail("Error generating documentation: %v", err)
+	}
+	opts.printf(Green+"Documentation written to %s\n"+Clear, outputPath)
+	return exitOK
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ajtroup1/DocMate/internal/lsp"
+	"github.com/ajtroup1/DocMate/internal/utils"
+)
+
+// Runs the language server over stdin and stdout. Nothing else may be printed to stdout, so
+// problems go to stderr, which editors show in their logs
+func runLsp(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("lsp", "", opts)
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+	opts.quiet = true
+
+	// Editors start the server wherever they like, so broken settings fall back to the defaults
+	// rather than leaving the editor without a server
+	config, err := opts.loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading settings, using the defaults: %v\n", err)
+		if config, err = utils.LoadConfig("", opts.overrides); err != nil {
+			return fail("Error reading settings: %v", err)
+		}
+	}
+	settings := config.Settings
+	if settings.ProjectPath, err = filepath.Abs(settings.ProjectPath); err != nil {
+		return fail("Error resolving project path: %v", err)
+	}
+
+	if err := lsp.New(os.Stdin, os.Stdout, settings).Run(ctx); err != nil {
+		return fail("Language server stopped: %v", err)
+	}
+	return exitOK
+}
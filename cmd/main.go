@@ -1,12 +1,12 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"log"
-
-	"github.com/ajtroup1/DocMate/internal/lexer"
-	"github.com/ajtroup1/DocMate/internal/parser"
-	"github.com/ajtroup1/DocMate/internal/utils"
+	"os"
+	"os/signal"
+	"strings"
 )
 
 const (
@@ -16,17 +16,111 @@ const (
 	Clear  = "\033[0m"
 )
 
+// Exit codes shared by every command
+const (
+	exitOK      = 0 // Success
+	exitFailure = 1 // Documentation errors were found, or coverage is below the minimum
+	exitUsage   = 2 // Unknown command or invalid flags
+	exitError   = 3 // DocMate itself failed, eg. unreadable settings or an unwritable output path
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, opts *options, args []string) int
+}
+
+var commands = []command{
+	{"init", "write a default settings file", runInit},
+	{"generate", "generate documentation from the project or a save file", runGenerate},
+	{"save", "write the documentation data to json", runSave},
+	{"lint", "report problems in DocMate comments", runLint},
+	{"check", "lint and check coverage without writing anything, for CI", runCheck},
+	{"coverage", "report how many exported identifiers are documented", runCoverage},
+	{"watch", "regenerate the documentation whenever the project changes", runWatch},
+	{"serve", "serve the HTML documentation and reload it in the browser on every change", runServe},
+	{"lsp", "run the language server over stdio for editors", runLsp},
+	{"tags", "print the reference of every header and tag as Markdown", runTags},
+	{"config", "show the merged settings and where each value came from", runConfig},
+	{"cache", "remove cached lexing and parsing results with `cache clean`", runCache},
+}
+
 func main() {
-	fmt.Print()
-	settings, err := utils.GetOrRetrieveSettings()
-	if err != nil {
-		log.Fatalf(Red+"Error retrieving / creating settings: %v\n"+Clear, err)
+	os.Exit(run(os.Args[1:]))
+}
+
+// Global flags may be given before or after the command, eg. `docmate -v lint` or `docmate lint -v`
+func run(args []string) int {
+	opts := newOptions()
+	flags := flag.NewFlagSet("docmate", flag.ContinueOnError)
+	opts.register(flags)
+	flags.Usage = func() { usage(flags) }
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
 	}
 
-	lexer := lexer.New(settings.IncludeTests, settings.ProjectPath)
-	comments, err := lexer.ExtractComments()
+	// Running DocMate without a command generates the documentation, as it always has
+	name := "generate"
+	if flags.NArg() > 0 {
+		name = flags.Arg(0)
+		args = flags.Args()[1:]
+	} else {
+		args = nil
+	}
+
+	// Interrupting DocMate cancels any lexing in progress
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	parser := parser.New(comments, settings.CapitalizeItems)
-	parser.ParseComments()
+	if name == "help" {
+		usage(flags)
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(ctx, opts, args)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, Red+"Unknown command `%s`"+Clear+"\n", name)
+	usage(flags)
+	return exitUsage
+}
+
+func usage(flags *flag.FlagSet) {
+	out := flags.Output()
+	fmt.Fprintln(out, "Usage: docmate [global flags] <command> [flags]")
+	fmt.Fprintln(out, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(out, "\nGlobal flags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(out, "\nRun `docmate <command> -h` for the flags of a command.")
+}
+
+// Creates the flag set of a command, which also accepts the global flags
+func newFlagSet(name, args string, opts *options) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), strings.TrimSpace("Usage: docmate "+name+" [flags] "+args))
+		fmt.Fprintln(flags.Output(), "\nFlags:")
+		flags.PrintDefaults()
+	}
+	opts.register(flags)
+	return flags
+}
+
+// The flag package has already printed the error and usage
+func parseError(err error) int {
+	if err == flag.ErrHelp {
+		return exitOK
+	}
+	return exitUsage
+}
 
+// Reports a failure of DocMate itself
+func fail(format string, args ...any) int {
+	fmt.Fprintf(os.Stderr, Red+format+Clear+"\n", args...)
+	return exitError
 }
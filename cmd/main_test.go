@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/utils"
+)
+
+const validSource = `package demo
+
+/***
+-- FUNC
+@func Add
+@desc Adds two numbers
+@param a: First
+@param b: Second
+@ret (int): The sum
+*/
+func Add(a, b int) int { return a + b }
+`
+
+// The FUNC block has no name, which is an error
+const invalidSource = `package demo
+
+/***
+-- FUNC
+@desc Adds two numbers
+*/
+func Add(a, b int) int { return a + b }
+`
+
+// Writes a project holding a single source file along with its settings, returning the path of
+// the settings file
+func writeProject(t *testing.T, source string) string {
+	dir := t.TempDir()
+	settings := `{"Project_Name": "Demo", "Project_Path": "` + filepath.ToSlash(dir) + `", "Output_Path": "` + filepath.ToSlash(filepath.Join(dir, "docs")) + `"}`
+	files := map[string]string{"settings.json": settings, "demo.go": source}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "settings.json")
+}
+
+func TestExitCodes(t *testing.T) {
+	for _, field := range utils.Fields {
+		t.Setenv(field.Env, "")
+		os.Unsetenv(field.Env)
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	valid := writeProject(t, validSource)
+	invalid := writeProject(t, invalidSource)
+	missing := filepath.Join(t.TempDir(), "settings.json")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "help", args: []string{"help"}, want: exitOK},
+		{name: "unknown command", args: []string{"publish"}, want: exitUsage},
+		{name: "unknown flag", args: []string{"lint", "-bogus"}, want: exitUsage},
+		{name: "command help", args: []string{"lint", "-h"}, want: exitOK},
+		{name: "init", args: []string{"-q", "init"}, want: exitOK},
+		{name: "init over an existing file", args: []string{"-q", "init"}, want: exitError},
+		{name: "init with an unknown format", args: []string{"init", "-format", "xml"}, want: exitUsage},
+		{name: "generate", args: []string{"-q", "-settings", valid, "generate"}, want: exitOK},
+		{name: "generate html", args: []string{"-q", "-settings", valid, "generate", "-format", "html"}, want: exitOK},
+		{name: "generate with an unknown format", args: []string{"-settings", valid, "generate", "-format", "pdf"}, want: exitUsage},
+		{name: "generate with errors", args: []string{"-q", "-settings", invalid, "generate"}, want: exitFailure},
+		{name: "generate without settings", args: []string{"-settings", missing, "generate"}, want: exitError},
+		{name: "generate from missing save data", args: []string{"-settings", valid, "generate", missing}, want: exitError},
+		{name: "save", args: []string{"-q", "-settings", valid, "save"}, want: exitOK},
+		{name: "save with errors", args: []string{"-q", "-settings", invalid, "save"}, want: exitFailure},
+		{name: "lint", args: []string{"-q", "-settings", valid, "lint"}, want: exitOK},
+		{name: "lint with errors", args: []string{"-q", "-settings", invalid, "lint"}, want: exitFailure},
+		{name: "check", args: []string{"-q", "-settings", valid, "check", "-min", "100"}, want: exitOK},
+		{name: "check with errors", args: []string{"-q", "-settings", invalid, "check"}, want: exitFailure},
+		{name: "coverage", args: []string{"-q", "-settings", valid, "coverage", "-min", "100"}, want: exitOK},
+		{name: "coverage below the minimum", args: []string{"-q", "-settings", invalid, "coverage", "-min", "100"}, want: exitFailure},
+		{name: "tags", args: []string{"tags"}, want: exitOK},
+		{name: "config show", args: []string{"-settings", valid, "config", "show"}, want: exitOK},
+		{name: "config without a subcommand", args: []string{"config"}, want: exitUsage},
+		{name: "cache clean", args: []string{"-q", "-settings", valid, "cache", "clean"}, want: exitOK},
+		{name: "cache without a subcommand", args: []string{"cache"}, want: exitUsage},
+	}
+
+	for _, test := range tests {
+		if got := run(test.args); got != test.want {
+			t.Errorf("%s: got exit code %d, want %d", test.name, got, test.want)
+		}
+	}
+}
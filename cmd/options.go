@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/ajtroup1/DocMate/internal/utils"
+)
+
+// Flags shared by every command
+type options struct {
+	settingsPath string
+	overrides    map[string]string // Settings given as flags, keyed by utils.Field.Key
+	verbose      bool
+	quiet        bool
+	noCache      bool
+}
+
+func newOptions() *options {
+	return &options{overrides: make(map[string]string)}
+}
+
+// Registers the global flags. Registering resets them to their defaults, so the values given
+// before the command are restored for the command's flag set
+func (o *options) register(flags *flag.FlagSet) {
+	settingsPath, verbose, quiet, noCache := o.settingsPath, o.verbose, o.quiet, o.noCache
+	flags.StringVar(&o.settingsPath, "settings", "", "path to the settings file, looked up from the project to the go.mod root by default")
+	flags.BoolVar(&o.verbose, "v", false, "report every file read")
+	flags.BoolVar(&o.quiet, "q", false, "only report errors")
+	flags.BoolVar(&o.noCache, "no-cache", false, "lex and parse every file instead of reusing unchanged results")
+	o.settingsPath, o.verbose, o.quiet, o.noCache = settingsPath, verbose, quiet, noCache
+
+	// Every setting can also be given as a flag, eg. `-output docs`
+	for _, field := range utils.Fields {
+		flags.Var(&settingFlag{field: field, overrides: o.overrides}, field.Flag, fmt.Sprintf("%s, overrides %s", field.Usage, field.Key))
+	}
+}
+
+// Merges the settings file, environment and flags
+func (o *options) loadConfig() (*utils.Config, error) {
+	path := o.settingsPath
+	if path == "" {
+		var err error
+		if path, err = utils.DiscoverSettings(o.overrides); err != nil {
+			return nil, err
+		}
+	}
+
+	if path != "" {
+		o.logf("Reading settings from %s\n", path)
+	}
+	return utils.LoadConfig(path, o.overrides)
+}
+
+// Prints progress that is only wanted with -v
+func (o *options) logf(format string, args ...any) {
+	if o.verbose && !o.quiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// Prints results that -q silences
+func (o *options) printf(format string, args ...any) {
+	if !o.quiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// Records a setting given on the command line, values are checked once the settings are merged
+type settingFlag struct {
+	field     utils.Field
+	overrides map[string]string
+}
+
+func (f *settingFlag) String() string {
+	if f.overrides == nil {
+		return ""
+	}
+	return f.overrides[f.field.Key]
+}
+
+func (f *settingFlag) Set(value string) error {
+	f.overrides[f.field.Key] = value
+	return nil
+}
+
+// Lets boolean settings be given without a value, eg. `-include-tests`
+func (f *settingFlag) IsBoolFlag() bool {
+	return f.field.IsBool
+}
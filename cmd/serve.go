@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ajtroup1/DocMate/internal/generator"
+	"github.com/ajtroup1/DocMate/internal/server"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Serves the HTML documentation from memory, rebuilding it and reloading open pages whenever
+// the project changes. Nothing is written to the output path
+func runServe(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("serve", "", opts)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	interval, debounce := watchFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+	if *interval <= 0 {
+		fmt.Fprintln(os.Stderr, Red+"-interval must be positive"+Clear)
+		return exitUsage
+	}
+
+	config, code := configOrFail(opts)
+	if config == nil {
+		return code
+	}
+
+	site := server.New()
+	// A failed build keeps serving the last site that built
+	build := func(settings *types.Settings) {
+		parser, diagnostics, err := parseProject(ctx, opts, settings)
+		if err != nil {
+			fail("Error extracting comments: %v", err)
+			return
+		}
+		printDiagnostics(opts, diagnostics)
+
+		pages, err := generator.New(parser.Packages, settings).RenderHTML()
+		if err != nil {
+			fail("Error generating documentation: %v", err)
+			return
+		}
+		site.SetPages(pages)
+	}
+	build(config.Settings)
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fail("Error listening on %s: %v", *addr, err)
+	}
+	httpServer := &http.Server{Handler: site}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+	opts.printf(Green+"Serving documentation at http://%s\n"+Clear, listener.Addr())
+
+	watchCtx, stop := context.WithCancel(ctx)
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- watchProject(watchCtx, opts, config, *interval, *debounce, build)
+	}()
+
+	code = exitOK
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			code = fail("Error serving documentation: %v", err)
+		}
+	case err := <-watchErr:
+		if err != nil {
+			code = fail("Error watching project: %v", err)
+		}
+	}
+
+	stop()
+	site.Close()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	httpServer.Shutdown(shutdownCtx)
+	return code
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ajtroup1/DocMate/internal/ignore"
+	"github.com/ajtroup1/DocMate/internal/types"
+	"github.com/ajtroup1/DocMate/internal/utils"
+	"github.com/ajtroup1/DocMate/internal/watch"
+)
+
+// Regenerates the documentation whenever a Go file, the settings file or the ignore file changes.
+// Unchanged files come from the cache, so only what changed is lexed and parsed again
+func runWatch(ctx context.Context, opts *options, args []string) int {
+	flags := newFlagSet("watch", "", opts)
+	format := flags.String("format", "markdown", "output format, `markdown` or `html`")
+	interval, debounce := watchFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return parseError(err)
+	}
+	if *format != "markdown" && *format != "md" && *format != "html" {
+		fmt.Fprintf(os.Stderr, Red+"Unknown format `%s`, expected `markdown` or `html`"+Clear+"\n", *format)
+		return exitUsage
+	}
+	if *interval <= 0 {
+		fmt.Fprintln(os.Stderr, Red+"-interval must be positive"+Clear)
+		return exitUsage
+	}
+
+	config, code := configOrFail(opts)
+	if config == nil {
+		return code
+	}
+
+	build := func(settings *types.Settings) {
+		parser, diagnostics, err := parseProject(ctx, opts, settings)
+		if err != nil {
+			fail("Error extracting comments: %v", err)
+			return
+		}
+		printDiagnostics(opts, diagnostics)
+		generate(opts, parser.Packages, settings, *format)
+	}
+	build(config.Settings)
+
+	opts.printf("Watching %s for changes, press Ctrl-C to stop\n", config.Settings.ProjectPath)
+	if err := watchProject(ctx, opts, config, *interval, *debounce, build); err != nil {
+		return fail("Error watching project: %v", err)
+	}
+	return exitOK
+}
+
+func watchFlags(flags *flag.FlagSet) (interval, debounce *time.Duration) {
+	interval = flags.Duration("interval", 500*time.Millisecond, "time between checks for changed files")
+	debounce = flags.Duration("debounce", 300*time.Millisecond, "how long files must stay unchanged before rebuilding")
+	return interval, debounce
+}
+
+// Calls build after every burst of changes until the context is done, reloading the settings
+// first when their file changed
+func watchProject(ctx context.Context, opts *options, config *utils.Config, interval, debounce time.Duration, build func(*types.Settings)) error {
+	// Listed on every poll so created files are noticed, and so the list follows the settings
+	list := func() ([]string, error) {
+		files, err := projectLexer(opts, config.Settings).Files()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, filepath.Join(config.Settings.ProjectPath, ignore.FileName))
+		if config.Path != "" {
+			files = append(files, config.Path)
+		}
+		return files, nil
+	}
+
+	watcher := &watch.Watcher{Poller: watch.NewPoller(list), Interval: interval, Debounce: debounce}
+	onChange := func(changed []string) {
+		opts.printf("\n%d file(s) changed\n", len(changed))
+		reload := false
+		for _, path := range changed {
+			opts.logf("  %s\n", path)
+			reload = reload || path == config.Path
+		}
+
+		// Invalid settings are reported and the last valid ones are kept until they're fixed
+		if reload {
+			next, _ := configOrFail(opts)
+			if next == nil {
+				return
+			}
+			config = next
+		}
+		build(config.Settings)
+	}
+	onError := func(err error) {
+		fmt.Fprintln(os.Stderr, Red+err.Error()+Clear)
+	}
+
+	return watcher.Run(ctx, onChange, onError)
+}
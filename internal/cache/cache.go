@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ajtroup1/DocMate/internal/parser"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Bump whenever lexing or parsing output changes, so caches written by older versions are
+// thrown away rather than trusted
+const Version = 5
+
+// Cache holds the lexed blocks and parsed units of a project's files, keyed by path and
+// the hash of their content. It implements lexer.Cache and parser.UnitCache
+type Cache struct {
+	path string
+	mu   sync.Mutex
+	data data
+	seen map[string]bool // Files whose entry is known to match their content in this run
+}
+
+// Layout of the cache file
+type data struct {
+	Version int               `json:"version"`
+	Key     string            `json:"key"` // Settings that change parsed units
+	Entries map[string]*entry `json:"entries"`
+}
+
+type entry struct {
+	Hash        string               `json:"hash"`
+	Comments    []types.CommentBlock `json:"comments,omitempty"`
+	Diagnostics []types.Diagnostic   `json:"diagnostics,omitempty"`
+	Unit        *parser.Unit         `json:"unit,omitempty"`
+}
+
+// Dir returns the directory every project's cache is kept in
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user cache directory: %v", err)
+	}
+	return filepath.Join(dir, "docmate"), nil
+}
+
+// Path returns the cache file of the project
+func Path(projectPath string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// Open loads the project's cache. A missing, unreadable or outdated cache starts out empty,
+// and units are dropped when the settings they were parsed with don't match key
+func Open(projectPath, key string) (*Cache, error) {
+	path, err := Path(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache{path: path, seen: make(map[string]bool)}
+	content, err := os.ReadFile(path)
+	if err == nil && json.Unmarshal(content, &c.data) == nil && c.data.Version == Version {
+		if c.data.Key != key {
+			for _, e := range c.data.Entries {
+				e.Unit = nil
+			}
+		}
+	} else {
+		c.data = data{}
+	}
+
+	c.data.Version = Version
+	c.data.Key = key
+	if c.data.Entries == nil {
+		c.data.Entries = make(map[string]*entry)
+	}
+	return c, nil
+}
+
+// Lookup returns the blocks and diagnostics of the file when its content is unchanged
+func (c *Cache) Lookup(path string, src []byte) ([]types.CommentBlock, []types.Diagnostic, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.data.Entries[path]
+	if !ok || e.Hash != hash(src) {
+		return nil, nil, false
+	}
+	c.seen[path] = true
+	return e.Comments, e.Diagnostics, true
+}
+
+// Store records the blocks and diagnostics lexed from the file's content
+func (c *Cache) Store(path string, src []byte, comments []types.CommentBlock, diagnostics []types.Diagnostic) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data.Entries[path] = &entry{Hash: hash(src), Comments: comments, Diagnostics: diagnostics}
+	c.seen[path] = true
+}
+
+// Unit returns the parsed unit of a file whose content matched its entry in this run
+func (c *Cache) Unit(path string) (*parser.Unit, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.data.Entries[path]
+	if !ok || !c.seen[path] || e.Unit == nil {
+		return nil, false
+	}
+	return e.Unit, true
+}
+
+// SetUnit records the parsed unit of a file looked up or stored in this run
+func (c *Cache) SetUnit(path string, unit *parser.Unit) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.data.Entries[path]; ok && c.seen[path] {
+		e.Unit = unit
+	}
+}
+
+// Save writes the cache, dropping the files that weren't read in this run
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path := range c.data.Entries {
+		if !c.seen[path] {
+			delete(c.data.Entries, path)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(c.data); err != nil {
+		return fmt.Errorf("failed to marshal cache: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	// Written to a temporary file first so an interrupted run never leaves a truncated cache
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write cache: %v", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write cache: %v", err)
+	}
+	return nil
+}
+
+// Clean removes the project's cache, or every project's cache when all is set
+func Clean(projectPath string, all bool) (string, error) {
+	path, err := Path(projectPath)
+	if all {
+		path, err = Dir()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if all {
+		err = os.RemoveAll(path)
+	} else if err = os.Remove(path); errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to remove %s: %v", path, err)
+	}
+	return path, nil
+}
+
+func hash(src []byte) string {
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/parser"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Keeps the cache out of the user's cache directory
+func useTempDir(t *testing.T) string {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LocalAppData", dir)
+	return t.TempDir()
+}
+
+func open(t *testing.T, project, key string) *Cache {
+	c, err := Open(project, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+var (
+	srcA     = []byte("package a\n")
+	srcB     = []byte("package b\n")
+	comments = []types.CommentBlock{{Filepath: "a.go", Package: "a"}}
+)
+
+// Stores a.go and b.go along with their units, then saves the cache
+func populate(t *testing.T, project, key string) {
+	c := open(t, project, key)
+	c.Store("a.go", srcA, comments, nil)
+	c.SetUnit("a.go", &parser.Unit{Path: "a.go"})
+	c.Store("b.go", srcB, nil, nil)
+	c.SetUnit("b.go", &parser.Unit{Path: "b.go"})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	project := useTempDir(t)
+	populate(t, project, "key")
+	c := open(t, project, "key")
+
+	if got, _, ok := c.Lookup("a.go", srcA); !ok || len(got) != 1 || got[0].Package != "a" {
+		t.Errorf("unchanged file: got %v, %t, want the stored blocks", got, ok)
+	}
+	if _, _, ok := c.Lookup("b.go", []byte("package b // edited\n")); ok {
+		t.Error("edited file: got a hit, want a miss")
+	}
+	if _, _, ok := c.Lookup("c.go", srcA); ok {
+		t.Error("unknown file: got a hit, want a miss")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int // Version the cache file is rewritten with, 0 to keep it
+		key     string
+		entries bool
+		units   bool
+	}{
+		{name: "same version and key", key: "key", entries: true, units: true},
+		{name: "settings key changed", key: "other", entries: true, units: false},
+		{name: "older version", version: Version - 1, key: "key", entries: false, units: false},
+		{name: "newer version", version: Version + 1, key: "key", entries: false, units: false},
+	}
+
+	for _, test := range tests {
+		project := useTempDir(t)
+		populate(t, project, "key")
+		if test.version != 0 {
+			rewriteVersion(t, project, test.version)
+		}
+
+		c := open(t, project, test.key)
+		_, _, hit := c.Lookup("a.go", srcA)
+		if hit != test.entries {
+			t.Errorf("%s: got lookup hit %t, want %t", test.name, hit, test.entries)
+		}
+		if _, ok := c.Unit("a.go"); ok != test.units {
+			t.Errorf("%s: got unit %t, want %t", test.name, ok, test.units)
+		}
+	}
+}
+
+func rewriteVersion(t *testing.T, project string, version int) {
+	path, err := Path(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d data
+	if err := json.Unmarshal(content, &d); err != nil {
+		t.Fatal(err)
+	}
+	d.Version = version
+	if content, err = json.Marshal(d); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Units are only trusted once the file's content was checked in this run
+func TestUnitUnseen(t *testing.T) {
+	project := useTempDir(t)
+	populate(t, project, "key")
+	c := open(t, project, "key")
+
+	if _, ok := c.Unit("a.go"); ok {
+		t.Error("before lookup: got a unit, want none")
+	}
+	c.SetUnit("a.go", &parser.Unit{Path: "replaced"})
+	if _, _, ok := c.Lookup("b.go", []byte("package b // edited\n")); ok {
+		t.Fatal("edited file: got a hit, want a miss")
+	}
+	if _, ok := c.Unit("b.go"); ok {
+		t.Error("after a miss: got a unit, want none")
+	}
+
+	c.Lookup("a.go", srcA)
+	if unit, ok := c.Unit("a.go"); !ok || unit.Path != "a.go" {
+		t.Errorf("after lookup: got %v, %t, want the stored unit", unit, ok)
+	}
+	if _, ok := c.Unit("c.go"); ok {
+		t.Error("unknown file: got a unit, want none")
+	}
+}
+
+func TestSavePrunes(t *testing.T) {
+	project := useTempDir(t)
+	populate(t, project, "key")
+
+	c := open(t, project, "key")
+	c.Lookup("a.go", srcA)
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = open(t, project, "key")
+	if _, _, ok := c.Lookup("a.go", srcA); !ok {
+		t.Error("a.go was read, want it kept")
+	}
+	if _, _, ok := c.Lookup("b.go", srcB); ok {
+		t.Error("b.go wasn't read, want it dropped")
+	}
+	if _, err := os.Stat(c.path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("got %v, want the temporary file renamed", err)
+	}
+}
+
+func TestClean(t *testing.T) {
+	project := useTempDir(t)
+	populate(t, project, "key")
+
+	path, err := Clean(project, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("got %v, want %s removed", err, path)
+	}
+	if _, err := Clean(project, false); err != nil {
+		t.Errorf("cleaning twice: got %v, want no error", err)
+	}
+}
@@ -0,0 +1,198 @@
+package coverage
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+
+	"github.com/ajtroup1/DocMate/internal/resolver"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Report is the documentation coverage of every exported identifier in the project
+type Report struct {
+	Packages []PackageReport
+	Covered  int
+	Total    int
+	// Files that couldn't be parsed are left out of the coverage and reported as DM013
+	Diagnostics []types.Diagnostic
+}
+
+type PackageReport struct {
+	Name    string
+	Files   []FileReport
+	Covered int
+	Total   int
+}
+
+type FileReport struct {
+	Path    string
+	Gaps    []Gap
+	Covered int
+	Total   int
+}
+
+// Gap is an exported identifier without a DocMate comment block
+type Gap struct {
+	Pos  types.Position
+	Kind string // "func", "method", "type", "const" or "var"
+	Name string // Methods are named `Type.Method`
+}
+
+// An exported identifier, keyed the same way as the bindings that document it
+type identifier struct {
+	kind string
+	recv string
+	name string
+	pos  token.Pos
+	desc string
+}
+
+// Build checks every exported identifier in the files against the blocks bound to declarations.
+// Files that fail to parse are skipped so the rest of the project is still covered
+func Build(files []string, bindings []resolver.Binding) *Report {
+	documented := make(map[string]bool)
+	for _, binding := range bindings {
+		documented[bindingKey(binding)] = true
+	}
+
+	report := &Report{}
+	fset := token.NewFileSet()
+
+	for _, path := range files {
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			report.skip(path, err)
+			continue
+		}
+
+		file := FileReport{Path: path}
+		for _, ident := range exportedIdentifiers(f) {
+			file.Total++
+			if documented[key(path, ident.kind, ident.recv, ident.name)] {
+				file.Covered++
+				continue
+			}
+
+			name := ident.name
+			if ident.recv != "" {
+				name = ident.recv + "." + name
+			}
+			pos := fset.Position(ident.pos)
+			file.Gaps = append(file.Gaps, Gap{
+				Pos:  types.Position{Filepath: path, Line: pos.Line, Column: pos.Column},
+				Kind: ident.desc,
+				Name: name,
+			})
+		}
+
+		// Files without exported identifiers don't affect coverage
+		if file.Total == 0 {
+			continue
+		}
+		report.addFile(f.Name.Name, file)
+	}
+
+	return report
+}
+
+// Percent returns the percentage of documented identifiers, an empty report is fully covered
+func (r *Report) Percent() float64 {
+	return percent(r.Covered, r.Total)
+}
+
+func (p *PackageReport) Percent() float64 {
+	return percent(p.Covered, p.Total)
+}
+
+func (f *FileReport) Percent() float64 {
+	return percent(f.Covered, f.Total)
+}
+
+func (r *Report) addFile(pkgName string, file FileReport) {
+	r.Covered += file.Covered
+	r.Total += file.Total
+
+	for i := range r.Packages {
+		if r.Packages[i].Name == pkgName {
+			r.Packages[i].Files = append(r.Packages[i].Files, file)
+			r.Packages[i].Covered += file.Covered
+			r.Packages[i].Total += file.Total
+			return
+		}
+	}
+
+	r.Packages = append(r.Packages, PackageReport{Name: pkgName, Files: []FileReport{file}, Covered: file.Covered, Total: file.Total})
+}
+
+// Records a file that couldn't be parsed, pointing at its first syntax error
+func (r *Report) skip(path string, err error) {
+	pos := types.Position{Filepath: path}
+	message := err.Error()
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		pos.Line, pos.Column = list[0].Pos.Line, list[0].Pos.Column
+		message = list[0].Msg
+	}
+	r.Diagnostics = append(r.Diagnostics, types.Diagnostic{
+		Pos:      pos,
+		Severity: types.SeverityWarning,
+		Code:     types.CodeSourceUnavailable,
+		Message:  "could not compute coverage: " + message,
+	})
+}
+
+func exportedIdentifiers(f *ast.File) []identifier {
+	var idents []identifier
+
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			recv := resolver.ReceiverName(d)
+			desc := "func"
+			if recv != "" {
+				desc = "method"
+			}
+			idents = append(idents, identifier{kind: resolver.KindFunc, recv: recv, name: d.Name.Name, pos: d.Name.Pos(), desc: desc})
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.IsExported() {
+						idents = append(idents, identifier{kind: resolver.KindType, name: spec.Name.Name, pos: spec.Name.Pos(), desc: "type"})
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						if name.IsExported() {
+							idents = append(idents, identifier{kind: resolver.KindVar, name: name.Name, pos: name.Pos(), desc: d.Tok.String()})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return idents
+}
+
+func bindingKey(binding resolver.Binding) string {
+	recv := ""
+	if fn, ok := binding.Decl.(*ast.FuncDecl); ok {
+		recv = resolver.ReceiverName(fn)
+	}
+	return key(binding.Pos.Filepath, binding.Kind, recv, binding.Name)
+}
+
+func key(path, kind, recv, name string) string {
+	return path + "\x00" + kind + "\x00" + recv + "\x00" + name
+}
+
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(covered) / float64(total) * 100
+}
@@ -0,0 +1,164 @@
+package coverage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/lexer"
+	"github.com/ajtroup1/DocMate/internal/parser"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+var sources = map[string]string{
+	"shop.go": `package shop
+
+/***
+-- FUNC
+@func Open
+*/
+func Open() {}
+
+func Close() {}
+
+/***
+-- TYPE
+@type Cart
+*/
+type Cart struct{}
+
+/***
+-- FUNC
+@func Add
+@rec Cart
+*/
+func (c *Cart) Add() {}
+
+func (c Cart) Remove() {}
+
+/***
+-- VAR
+@var Default
+*/
+var Default Cart
+
+const Limit = 10
+
+func helper() {}
+`,
+	"item.go": `package shop
+
+type Item struct{}
+`,
+	"util.go": `package util
+
+/***
+-- FUNC
+@func Sum
+*/
+func Sum() {}
+
+var internal int
+`,
+	"empty.go": `package util
+
+func unexported() {}
+`,
+	"broken.go": `package util
+
+func Broken( {}
+`,
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	var comments []types.CommentBlock
+	for _, name := range []string{"shop.go", "item.go", "util.go", "empty.go", "broken.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(sources[name]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+		blocks, _ := lexer.ExtractSource(path, []byte(sources[name]))
+		comments = append(comments, blocks...)
+	}
+	p := parser.New(comments, false)
+	p.ParseComments()
+
+	report := Build(files, p.Bindings)
+
+	wantFiles := map[string]struct {
+		covered, total int
+		gaps           []string
+	}{
+		"shop.go": {4, 7, []string{"9:6 func Close", "24:15 method Cart.Remove", "32:7 const Limit"}},
+		"item.go": {0, 1, []string{"3:6 type Item"}},
+		"util.go": {1, 1, nil},
+	}
+	got := make(map[string]bool)
+	for _, pkg := range report.Packages {
+		for _, file := range pkg.Files {
+			name := filepath.Base(file.Path)
+			got[name] = true
+			want, ok := wantFiles[name]
+			if !ok {
+				t.Errorf("unexpected file %s in the report", name)
+				continue
+			}
+			var gaps []string
+			for _, gap := range file.Gaps {
+				gaps = append(gaps, fmt.Sprintf("%d:%d %s %s", gap.Pos.Line, gap.Pos.Column, gap.Kind, gap.Name))
+			}
+			if file.Covered != want.covered || file.Total != want.total || !reflect.DeepEqual(gaps, want.gaps) {
+				t.Errorf("%s: got %d/%d gaps %q, want %d/%d gaps %q", name, file.Covered, file.Total, gaps, want.covered, want.total, want.gaps)
+			}
+		}
+	}
+	for name := range wantFiles {
+		if !got[name] {
+			t.Errorf("%s is missing from the report", name)
+		}
+	}
+
+	packages := map[string]float64{"shop": 50, "util": 100}
+	for _, pkg := range report.Packages {
+		if want, ok := packages[pkg.Name]; !ok || pkg.Percent() != want {
+			t.Errorf("package %s: got %.1f%%, want %.1f%%", pkg.Name, pkg.Percent(), want)
+		}
+	}
+	if len(report.Packages) != len(packages) {
+		t.Errorf("got %d packages, want %d", len(report.Packages), len(packages))
+	}
+	if report.Covered != 5 || report.Total != 9 {
+		t.Errorf("got %d/%d, want 5/9", report.Covered, report.Total)
+	}
+
+	// The unparseable file is skipped rather than failing the whole report
+	if len(report.Diagnostics) != 1 {
+		t.Fatalf("got diagnostics %v, want one for broken.go", report.Diagnostics)
+	}
+	d := report.Diagnostics[0]
+	if d.Code != types.CodeSourceUnavailable || filepath.Base(d.Pos.Filepath) != "broken.go" || d.Pos.Line != 3 {
+		t.Errorf("got %v, want DM013 at broken.go:3", d)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		covered, total int
+		want           float64
+	}{
+		{0, 0, 100},
+		{0, 4, 0},
+		{1, 4, 25},
+		{3, 3, 100},
+	}
+	for _, test := range tests {
+		if got := percent(test.covered, test.total); got != test.want {
+			t.Errorf("percent(%d, %d): got %v, want %v", test.covered, test.total, got, test.want)
+		}
+	}
+}
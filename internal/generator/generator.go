@@ -0,0 +1,14 @@
+package generator
+
+import (
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+type Generator struct {
+	packages []types.Package
+	settings *types.Settings
+}
+
+func New(pkgs []types.Package, settings *types.Settings) *Generator {
+	return &Generator{packages: pkgs, settings: settings}
+}
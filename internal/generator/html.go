@@ -0,0 +1,163 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Name of the directory the static site is written to inside the output path
+const htmlDirName = "site"
+
+// Templates the site is rendered from, the layout defines the header and footer of every page
+const (
+	htmlLayoutTemplate  = "layout.html.tmpl"
+	htmlIndexTemplate   = "index.html.tmpl"
+	htmlPackageTemplate = "package.html.tmpl"
+)
+
+// GenerateHTML writes the documentation as a static site to the output path
+func (g *Generator) GenerateHTML() (string, error) {
+	pages, err := g.RenderHTML()
+	if err != nil {
+		return "", err
+	}
+
+	outputDir := filepath.Join(g.settings.OutputPath, htmlDirName)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	for name, content := range pages {
+		path := filepath.Join(outputDir, name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %v", path, err)
+		}
+	}
+
+	return outputDir, nil
+}
+
+// RenderHTML renders every page of the site in memory, keyed by file name
+func (g *Generator) RenderHTML() (map[string][]byte, error) {
+	tmpl, paths, err := g.loadHTMLTemplates(htmlLayoutTemplate, htmlIndexTemplate, htmlPackageTemplate)
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string][]byte)
+
+	index, err := renderPage(tmpl, paths[htmlIndexTemplate], templateData{Settings: g.settings, Packages: g.packages})
+	if err != nil {
+		return nil, err
+	}
+	pages["index.html"] = index
+
+	for i := range g.packages {
+		pkg := &g.packages[i]
+		content, err := renderPage(tmpl, paths[htmlPackageTemplate], templateData{Settings: g.settings, Packages: g.packages, Package: pkg})
+		if err != nil {
+			return nil, err
+		}
+		pages[pageName(*pkg)] = content
+	}
+
+	return pages, nil
+}
+
+func renderPage(tmpl *template.Template, name string, data templateData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func pageName(pkg types.Package) string {
+	return "pkg-" + strings.ToLower(pkg.Name) + ".html"
+}
+
+// Anchors of the items on the package pages, told apart by where each item is declared
+type anchorIDs map[anchorKey]string
+
+type anchorKey struct {
+	id  string // Anchor the item has when its name is unique, eg. `type-Config`
+	pos types.Position
+}
+
+// Packages are grouped by name, so a package such as `main` may span several directories that
+// declare the same names. Every repeat after the first gets a numbered anchor, eg. `type-Config-2`
+func uniqueIDs(pkgs []types.Package) anchorIDs {
+	ids := make(anchorIDs)
+	for _, pkg := range pkgs {
+		seen := make(map[string]int)
+		add := func(id string, pos types.Position) {
+			key := anchorKey{id: id, pos: pos}
+			if _, ok := ids[key]; ok {
+				return
+			}
+			if seen[id]++; seen[id] > 1 {
+				id = fmt.Sprintf("%s-%d", id, seen[id])
+			}
+			ids[key] = id
+		}
+
+		for _, typ := range pkg.Types {
+			add(typeID(typ), typ.Pos)
+		}
+		for _, variable := range pkg.Vars {
+			add(varID(variable), variable.Pos)
+		}
+		for _, function := range pkg.Funcs {
+			add(funcID(function), function.Pos)
+		}
+		for _, file := range pkg.Files {
+			add(fileID(file), filePos(file))
+		}
+	}
+	return ids
+}
+
+// The anchor of the item declared at pos, falling back to id for items outside the packages
+func (ids anchorIDs) id(id string, pos types.Position) string {
+	if unique, ok := ids[anchorKey{id: id, pos: pos}]; ok {
+		return unique
+	}
+	return id
+}
+
+// Files without a FILE block have no position, their path tells them apart instead
+func filePos(file types.File) types.Position {
+	return types.Position{Filepath: file.Path}
+}
+
+func typeID(typ types.Type) string {
+	return "type-" + typ.Name
+}
+
+func varID(variable types.Variable) string {
+	return "var-" + variable.Name
+}
+
+func fileID(file types.File) string {
+	return "file-" + file.Name
+}
+
+func funcID(function types.Function) string {
+	if function.Receiver != nil {
+		return "method-" + function.Receiver.Name + "-" + function.Name
+	}
+	return "func-" + function.Name
+}
+
+// Methods are shown with their receiver, eg. `Handler.Serve`
+func funcName(function types.Function) string {
+	if function.Receiver != nil {
+		return function.Receiver.Name + "." + function.Name
+	}
+	return function.Name
+}
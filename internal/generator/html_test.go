@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+func TestRenderHTML(t *testing.T) {
+	pkgs := parseSources(t, map[string]string{"shapes.go": markdownSource})
+	pages, err := New(pkgs, &types.Settings{ProjectName: "Shapes", ProjectDesc: "Measures shapes"}).RenderHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("got pages %v, want index.html and pkg-shapes.html", slices.Collect(maps.Keys(pages)))
+	}
+
+	tests := []struct {
+		name string
+		page string
+		want []string // In the order they appear on the page
+	}{
+		{
+			name: "index layout",
+			page: "index.html",
+			want: []string{
+				"<title>Shapes</title>",
+				`<nav>`, `<a class="project" href="index.html">Shapes</a>`, `<li><a href="pkg-shapes.html">shapes</a>`, `</nav>`,
+				"<main>", "<h1>Shapes</h1>", "<h3>Measures shapes</h3>",
+				`<li><a href="pkg-shapes.html">shapes</a> - <span class="desc">Geometry helpers</span></li>`,
+				"</main>", "</html>",
+			},
+		},
+		{
+			name: "package sidebar",
+			page: "pkg-shapes.html",
+			want: []string{
+				"<title>shapes - Shapes</title>",
+				`<li class="current"><a href="pkg-shapes.html">shapes</a>`,
+				`<li><a href="#type-Rect">Rect</a></li>`,
+				`<li><a href="#var-Unit">Unit</a></li>`,
+				`<li><a href="#method-Rect-Scale">Rect.Scale</a></li>`,
+				"</nav>",
+			},
+		},
+		{
+			name: "package anchors",
+			page: "pkg-shapes.html",
+			want: []string{
+				`<h2 id="dependencies">Dependencies</h2>`,
+				`<h2 id="types">Types</h2>`, `<div class="item" id="type-Rect">`,
+				`<h2 id="variables">Variables</h2>`, `<div class="item" id="var-Unit">`,
+				`<h2 id="functions">Functions</h2>`, `<div class="item" id="method-Rect-Scale">`,
+				`<p>Receiver: <a href="#type-Rect"><code>Rect</code></a></p>`,
+				`<li class="error">(error) <span class="desc">When the factor is negative</span></li>`,
+				`<h2 id="files">Files</h2>`, `<div class="item" id="file-shapes.go">`,
+				"</main>",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		page := string(pages[test.page])
+		rest := page
+		for _, want := range test.want {
+			i := strings.Index(rest, want)
+			if i == -1 {
+				t.Errorf("%s: %s has no %q after the previous ones, got\n%s", test.name, test.page, want, page)
+				break
+			}
+			rest = rest[i+len(want):]
+		}
+	}
+}
+
+func TestUniqueAnchors(t *testing.T) {
+	// Both `main` packages land on the same page
+	source := "package main\n\n/***\n-- TYPE\n@type Config\n*/\ntype Config struct{}\n\n/***\n-- FUNC\n@func main\n*/\nfunc main() {}\n"
+	pkgs := parseSources(t, map[string]string{"a/main.go": source, "b/main.go": source})
+	pages, err := New(pkgs, &types.Settings{ProjectName: "Tools"}).RenderHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := string(pages["pkg-main.html"])
+	for _, id := range []string{"type-Config", "type-Config-2", "func-main", "func-main-2", "file-main.go", "file-main.go-2"} {
+		if got := strings.Count(page, `id="`+id+`"`); got != 1 {
+			t.Errorf("got %d elements with id %q, want 1", got, id)
+		}
+	}
+	// Files aren't listed in the sidebar
+	for _, id := range []string{"type-Config", "type-Config-2", "func-main", "func-main-2"} {
+		if !strings.Contains(page, `href="#`+id+`"`) {
+			t.Errorf("sidebar has no link to %q", id)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Name of the generated Markdown document inside the output path
+const markdownFileName = "documentation.md"
+
+// Template the Markdown document is rendered from
+const markdownTemplate = "markdown.md.tmpl"
+
+// GenerateMarkdown writes the documentation to a Markdown file in the output path
+func (g *Generator) GenerateMarkdown() (string, error) {
+	content, err := g.RenderMarkdown()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(g.settings.OutputPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	outputPath := filepath.Join(g.settings.OutputPath, markdownFileName)
+	if err := os.WriteFile(outputPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %v", outputPath, err)
+	}
+
+	return outputPath, nil
+}
+
+// RenderMarkdown renders the Markdown document in memory
+func (g *Generator) RenderMarkdown() ([]byte, error) {
+	tmpl, paths, err := g.loadTextTemplates(markdownTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	data := templateData{Settings: g.settings, Packages: g.packages}
+	if err := tmpl.ExecuteTemplate(&buf, paths[markdownTemplate], data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RenderMarkdownItem renders a single package, file, type, variable or function the way it
+// appears in the Markdown document. Packages are rendered without their items
+func (g *Generator) RenderMarkdownItem(item any) ([]byte, error) {
+	var name string
+	switch item.(type) {
+	case types.Package:
+		name = "package"
+	case types.File:
+		name = "file"
+	case types.Type:
+		name = "type"
+	case types.Variable:
+		name = "variable"
+	case types.Function:
+		name = "function"
+	default:
+		return nil, fmt.Errorf("no Markdown template for %T", item)
+	}
+
+	tmpl, _, err := g.loadTextTemplates(markdownTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, item); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func typeSuffix(typ string) string {
+	if typ == "" {
+		return ""
+	}
+	return " (" + typ + ")"
+}
+
+// Converts a heading into the anchor Markdown renderers generate for it
+func anchor(heading string) string {
+	var sb strings.Builder
+
+	for _, ch := range strings.ToLower(heading) {
+		switch {
+		case ch == ' ':
+			sb.WriteRune('-')
+		case ch == '-' || ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9'):
+			sb.WriteRune(ch)
+		}
+	}
+
+	return sb.String()
+}
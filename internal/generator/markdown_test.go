@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/lexer"
+	"github.com/ajtroup1/DocMate/internal/parser"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// A small package touching every section of design/Example.md
+const markdownSource = `package shapes
+
+/***
+-- PKG
+@pkg shapes
+@desc Geometry helpers
+@usage Import it to measure shapes
+@dep {
+	@name Testify
+	@desc Used in the tests
+	@link https://github.com/stretchr/testify
+	@import github.com/stretchr/testify
+}
+*/
+
+/***
+-- TYPE
+@type Rect
+@desc An axis-aligned rectangle
+@field W: Width
+@field H: Height
+*/
+type Rect struct{ W, H int }
+
+/***
+-- VAR
+@var Unit
+@desc The unit square
+*/
+var Unit = Rect{W: 1, H: 1}
+
+/***
+-- FUNC
+@func (r Rect) Scale
+@desc Scales the rectangle
+@param by: Factor to scale by
+@ret (Rect): The scaled rectangle
+@ret (error): When the factor is negative
+@res 400 Bad Request - The factor is negative
+@ex r.Scale(2)
+*/
+func (r Rect) Scale(by int) (Rect, error) { return Rect{W: r.W * by, H: r.H * by}, nil }
+`
+
+// Parses the sources, keyed by file name, into the packages they document. Files are lexed in
+// name order so the packages list them the same way every run
+func parseSources(t *testing.T, sources map[string]string) []types.Package {
+	var comments []types.CommentBlock
+	files := map[string][]byte{}
+	for _, name := range slices.Sorted(maps.Keys(sources)) {
+		blocks, diagnostics := lexer.ExtractSource(name, []byte(sources[name]))
+		if len(diagnostics) > 0 {
+			t.Fatalf("unexpected diagnostics while lexing %v", diagnostics)
+		}
+		comments = append(comments, blocks...)
+		files[name] = []byte(sources[name])
+	}
+
+	p := parser.New(comments, false)
+	p.Sources = files
+	p.ParseComments()
+	if len(p.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics while parsing %v", p.Diagnostics)
+	}
+	return p.Packages
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	pkgs := parseSources(t, map[string]string{"shapes.go": markdownSource})
+	settings := &types.Settings{ProjectName: "Shapes", ProjectDesc: "Measures shapes"}
+	got, err := New(pkgs, settings).RenderMarkdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("testdata/shapes.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotLines, wantLines := strings.Split(string(got), "\n"), strings.Split(string(want), "\n")
+	for i := range max(len(gotLines), len(wantLines)) {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			t.Fatalf("line %d: got %q, want %q", i+1, g, w)
+		}
+	}
+}
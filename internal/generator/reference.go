@@ -0,0 +1,25 @@
+package generator
+
+import (
+	"bytes"
+
+	"github.com/ajtroup1/DocMate/internal/schema"
+)
+
+// Template the tag reference is rendered from
+const referenceTemplate = "reference.md.tmpl"
+
+// RenderReference renders the reference of every header and tag DocMate accepts
+func (g *Generator) RenderReference() ([]byte, error) {
+	tmpl, paths, err := g.loadTextTemplates(referenceTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, paths[referenceTemplate], schema.Headers); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
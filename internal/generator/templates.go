@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Default layouts, any of them can be replaced by a file of the same name in Settings.TemplateDir
+//
+//go:embed templates
+var defaultTemplates embed.FS
+
+const defaultTemplateDir = "templates"
+
+// Data handed to every template, Package is only set on package pages
+type templateData struct {
+	Settings *types.Settings
+	Packages []types.Package
+	Package  *types.Package
+}
+
+// Helpers available to both the Markdown and HTML templates
+var templateFuncs = map[string]any{
+	"add":        func(a, b int) int { return a + b },
+	"anchor":     anchor,
+	"typeSuffix": typeSuffix,
+	"indent":     indent,
+	"pageName":   pageName,
+	"funcName":   funcName,
+}
+
+// The shared helpers along with the ones reading the settings or packages. Items are rendered by
+// templates of their own, eg. "meta", so the settings can't always come from the data
+func (g *Generator) funcs() map[string]any {
+	ids := uniqueIDs(g.packages)
+	funcs := map[string]any{
+		"customTagsTitle": func() string { return g.settings.CustomTagsTitle },
+		"typeID":          func(typ types.Type) string { return ids.id(typeID(typ), typ.Pos) },
+		"varID":           func(variable types.Variable) string { return ids.id(varID(variable), variable.Pos) },
+		"funcID":          func(function types.Function) string { return ids.id(funcID(function), function.Pos) },
+		"fileID":          func(file types.File) string { return ids.id(fileID(file), filePos(file)) },
+	}
+	for name, fn := range templateFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// Parses the Markdown templates. Each template is named after the file it was read from, so
+// parse and execution errors point at the template file and line
+func (g *Generator) loadTextTemplates(names ...string) (*texttemplate.Template, map[string]string, error) {
+	root := texttemplate.New("docmate")
+	funcs := texttemplate.FuncMap(g.funcs())
+	// Lets a defined template be rendered into a string, eg. to indent it
+	funcs["include"] = func(name string, data any) (string, error) {
+		var buf bytes.Buffer
+		err := root.ExecuteTemplate(&buf, name, data)
+		return buf.String(), err
+	}
+	root.Funcs(funcs)
+
+	paths := make(map[string]string)
+	for _, name := range names {
+		path, content, err := g.readTemplate(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := root.New(path).Parse(content); err != nil {
+			return nil, nil, err
+		}
+		paths[name] = path
+	}
+
+	return root, paths, nil
+}
+
+// Parses the HTML templates, see loadTextTemplates
+func (g *Generator) loadHTMLTemplates(names ...string) (*htmltemplate.Template, map[string]string, error) {
+	root := htmltemplate.New("docmate").Funcs(htmltemplate.FuncMap(g.funcs()))
+
+	paths := make(map[string]string)
+	for _, name := range names {
+		path, content, err := g.readTemplate(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := root.New(path).Parse(content); err != nil {
+			return nil, nil, err
+		}
+		paths[name] = path
+	}
+
+	return root, paths, nil
+}
+
+// Reads a template from the override directory, falling back to the embedded default
+func (g *Generator) readTemplate(name string) (string, string, error) {
+	if g.settings.TemplateDir != "" {
+		path := filepath.Join(g.settings.TemplateDir, name)
+		content, err := os.ReadFile(path)
+		if err == nil {
+			return path, string(content), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", fmt.Errorf("failed to read template %s: %v", path, err)
+		}
+	}
+
+	// Embedded paths always use forward slashes
+	path := defaultTemplateDir + "/" + name
+	content, err := defaultTemplates.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read default template %s: %v", path, err)
+	}
+	return path, string(content), nil
+}
+
+// Prefixes every non-empty line with n spaces
+func indent(n int, text string) string {
+	prefix := strings.Repeat(" ", n)
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
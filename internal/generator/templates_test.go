@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, markdownTemplate, "# {{.Settings.ProjectName}} from an override\n")
+	writeTemplate(t, dir, htmlIndexTemplate, `{{template "header" .}}<p id="custom">{{len .Packages}} packages</p>{{template "footer" .}}`)
+
+	pkgs := []types.Package{{Name: "demo"}}
+	g := New(pkgs, &types.Settings{ProjectName: "Demo", TemplateDir: dir})
+
+	content, err := g.RenderMarkdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# Demo from an override\n" {
+		t.Errorf("got %q, want the override rendered", content)
+	}
+
+	pages, err := g.RenderHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(pages["index.html"]), `<p id="custom">1 packages</p>`) {
+		t.Errorf("index.html doesn't use the override:\n%s", pages["index.html"])
+	}
+	// The layout and package page weren't overridden, so the embedded ones are used
+	if !strings.Contains(string(pages["index.html"]), "<!DOCTYPE html>") || !strings.Contains(string(pages["pkg-demo.html"]), "Package <code>demo</code>") {
+		t.Errorf("the embedded templates weren't used for the rest of the site")
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string // Template file and line
+	}{
+		{name: "parse error", content: "# {{.Settings.ProjectName}}\n\n{{end}}\n", want: markdownTemplate + ":3:"},
+		{name: "execution error", content: "# Title\n{{.Settings.Missing}}\n", want: markdownTemplate + ":2:"},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		writeTemplate(t, dir, markdownTemplate, test.content)
+		_, err := New(nil, &types.Settings{TemplateDir: dir}).RenderMarkdown()
+		if want := "template: " + filepath.Join(dir, test.want); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want an error at %s", test.name, err, want)
+		}
+	}
+}
+
+func TestMissingTemplateDir(t *testing.T) {
+	// Files missing from the override directory fall back to the embedded ones
+	content, err := New(nil, &types.Settings{ProjectName: "Demo", TemplateDir: t.TempDir()}).RenderMarkdown()
+	if err != nil || !strings.HasPrefix(string(content), "# Demo\n") {
+		t.Errorf("got %q, %v, want the embedded layout", content, err)
+	}
+}
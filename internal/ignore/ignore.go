@@ -0,0 +1,158 @@
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// Name of the ignore file read from the project's root
+const FileName = ".docmateignore"
+
+// Matcher decides whether a path is ignored using gitignore-style patterns, where the last
+// matching pattern wins
+type Matcher struct {
+	patterns []pattern
+}
+
+type pattern struct {
+	negate   bool     // `!pattern` re-includes what an earlier pattern ignored
+	dirOnly  bool     // `pattern/` only matches directories
+	anchored bool     // Patterns containing a `/` match from the root, others match any base name
+	segments []string // Split on `/`, `**` matches any number of directories
+}
+
+// New parses the patterns, skipping blank lines and `#` comments
+func New(lines ...string) *Matcher {
+	m := &Matcher{}
+	for _, line := range lines {
+		if p, ok := parse(line); ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+	return m
+}
+
+// ReadFile reads the patterns of an ignore file, a missing file has none
+func ReadFile(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filePath, err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filePath, err)
+	}
+	return lines, nil
+}
+
+// Check reports a malformed glob in the pattern
+func Check(line string) error {
+	p, ok := parse(line)
+	if !ok {
+		return nil
+	}
+	for _, segment := range p.segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid pattern `%s`", line)
+		}
+	}
+	return nil
+}
+
+// Match reports whether the slash-separated path, relative to the root, is ignored
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		if p.matches(relPath, isDir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// MatchAny reports whether the path or any of its parent directories matches a non-negated
+// pattern, eg. `internal/` matches `internal/lexer/lexer.go`
+func (m *Matcher) MatchAny(relPath string) bool {
+	if m.Match(relPath, false) {
+		return true
+	}
+	for dir := path.Dir(relPath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if m.Match(dir, true) {
+			return true
+		}
+	}
+	return false
+}
+
+func parse(line string) (pattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+
+	var p pattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// `\#` and `\!` match names starting with those characters
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	p.anchored = strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return pattern{}, false
+	}
+
+	p.segments = strings.Split(line, "/")
+	return p, true
+}
+
+func (p pattern) matches(relPath string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if !p.anchored {
+		ok, _ := path.Match(p.segments[0], path.Base(relPath))
+		return ok
+	}
+	return matchSegments(p.segments, strings.Split(relPath, "/"))
+}
+
+func matchSegments(patterns, parts []string) bool {
+	if len(patterns) == 0 {
+		return len(parts) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(patterns[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(patterns[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(patterns[1:], parts[1:])
+}
@@ -0,0 +1,83 @@
+package ignore
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		// Patterns without a `/` match the base name at any depth
+		{[]string{"*.pb.go"}, "api.pb.go", false, true},
+		{[]string{"*.pb.go"}, "internal/api/api.pb.go", false, true},
+		{[]string{"*.pb.go"}, "api.go", false, false},
+		// A `/` anchors the pattern to the root
+		{[]string{"/main.go"}, "main.go", false, true},
+		{[]string{"/main.go"}, "cmd/main.go", false, false},
+		{[]string{"cmd/*.go"}, "cmd/main.go", false, true},
+		{[]string{"cmd/*.go"}, "tools/cmd/main.go", false, false},
+		{[]string{"cmd/*.go"}, "cmd/tool/main.go", false, false},
+		// A trailing `/` only matches directories
+		{[]string{"build/"}, "build", true, true},
+		{[]string{"build/"}, "build", false, false},
+		{[]string{"build/"}, "internal/build", true, true},
+		{[]string{"internal/gen/"}, "internal/gen", true, true},
+		{[]string{"internal/gen/"}, "pkg/internal/gen", true, false},
+		// `**` matches any number of directories, including none
+		{[]string{"**/mocks"}, "mocks", true, true},
+		{[]string{"**/mocks"}, "a/b/mocks", true, true},
+		{[]string{"internal/**/*.go"}, "internal/a.go", false, true},
+		{[]string{"internal/**/*.go"}, "internal/a/b/c.go", false, true},
+		{[]string{"internal/**/*.go"}, "cmd/a.go", false, false},
+		{[]string{"docs/**"}, "docs/a/b.go", false, true},
+		// `!` re-includes, and the last matching pattern wins
+		{[]string{"*.go", "!keep.go"}, "keep.go", false, false},
+		{[]string{"*.go", "!keep.go"}, "drop.go", false, true},
+		{[]string{"!keep.go", "*.go"}, "keep.go", false, true},
+		{[]string{"testdata/", "!testdata/", "testdata/"}, "testdata", true, true},
+		{[]string{"testdata/", "!testdata/"}, "testdata", true, false},
+		// Blank lines and comments are skipped, `\` escapes a leading `#` or `!`
+		{[]string{"", "# main.go"}, "main.go", false, false},
+		{[]string{`\#notes.go`}, "#notes.go", false, true},
+		{[]string{`\!bang.go`}, "!bang.go", false, true},
+		{[]string{"main.go   "}, "main.go", false, true},
+	}
+
+	for _, test := range tests {
+		if got := New(test.patterns...).Match(test.path, test.isDir); got != test.want {
+			t.Errorf("%q matching %s (dir %t): got %t, want %t", test.patterns, test.path, test.isDir, got, test.want)
+		}
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	m := New("internal/", "!internal/keep.go")
+	tests := map[string]bool{
+		"internal/lexer/lexer.go": true,
+		"internal/keep.go":        true, // The directory is still ignored
+		"cmd/main.go":             false,
+	}
+	for path, want := range tests {
+		if got := m.MatchAny(path); got != want {
+			t.Errorf("%s: got %t, want %t", path, got, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := map[string]bool{
+		"**/*.go":   true,
+		"[a-z]*.go": true,
+		"# [":       true,
+		"[abc":      false,
+		"cmd/[":     false,
+		`a\`:        false,
+	}
+	for pattern, valid := range tests {
+		if err := Check(pattern); (err == nil) != valid {
+			t.Errorf("%q: got error %v, want valid %t", pattern, err, valid)
+		}
+	}
+}
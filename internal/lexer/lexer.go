@@ -2,63 +2,205 @@ package lexer
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"unicode"
 
+	"github.com/ajtroup1/DocMate/internal/ignore"
 	"github.com/ajtroup1/DocMate/internal/types"
 )
 
 type Lexer struct {
-	src          string
-	position     int
-	readPosition int
-	ch           byte
 	includeTests bool
 	projectPath  string
+	Diagnostics  []types.Diagnostic
+	Verbose      bool     // Reports every file read and how many blocks it holds
+	Include      []string // Globs of the files to read, every file when empty
+	Exclude      []string // Gitignore-style patterns of files and directories to skip
+	Workers      int      // Files lexed at once, GOMAXPROCS when zero
+	Cache        Cache    // Files found in the cache skip lexing, nil to lex every file
 }
 
+// Cache stores the blocks and diagnostics of files whose content hasn't changed. It is used
+// by several workers at once
+type Cache interface {
+	Lookup(path string, src []byte) ([]types.CommentBlock, []types.Diagnostic, bool)
+	Store(path string, src []byte, comments []types.CommentBlock, diagnostics []types.Diagnostic)
+}
+
+// Skipped unless re-included with a `!` pattern
+var defaultExcludes = []string{"vendor/", "testdata/", ".*/"}
+
 func New(include bool, path string) *Lexer {
 	return &Lexer{includeTests: include, projectPath: path}
 }
 
-func (e *Lexer) ExtractComments() ([]types.CommentBlock, error) {
-	fmt.Print()
+func (e *Lexer) ExtractComments(ctx context.Context) ([]types.CommentBlock, error) {
+	files, err := e.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	return e.ExtractCommentsFrom(ctx, files)
+}
+
+// ExtractCommentsFrom lexes the files returned by Files in a pool of workers. Blocks and
+// diagnostics are merged in the order of files, and the first file that can't be read
+// cancels the others
+func (e *Lexer) ExtractCommentsFrom(ctx context.Context, files []string) ([]types.CommentBlock, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	workers := e.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(files))
+
+	results := make([]*fileLexer, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				f, err := e.lexFile(files[i])
+				if err != nil {
+					cancel(err)
+					return
+				}
+				results[i] = f
+			}
+		}()
+	}
+
+feed:
+	for i := range files {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
 	var comments []types.CommentBlock
+	for _, f := range results {
+		e.logf("Reading comments from %s\n", f.path)
+		if len(f.comments) > 0 {
+			e.logf("%d comments found in `%s`\n", len(f.comments), f.path)
+			comments = append(comments, f.comments...)
+		}
+		e.Diagnostics = append(e.Diagnostics, f.diagnostics...)
+	}
+
+	return comments, nil
+}
+
+// Files walks the project and returns every Go file the lexer reads, in lexical order. Vendored,
+// testdata, hidden and generated files are skipped along with anything matched by Exclude or
+// the project's .docmateignore
+func (e *Lexer) Files() ([]string, error) {
+	var files []string
+
+	excludes, includes, err := e.matchers()
+	if err != nil {
+		return nil, err
+	}
 
-	err := filepath.WalkDir(e.projectPath, func(path string, entry os.DirEntry, err error) error {
+	err = filepath.WalkDir(e.projectPath, func(path string, entry os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		// if !entry.IsDir() && entry.Name() == "go.mod" {
-		// 	projectName, err = e.extractGoMod(path)
-		// 	if err != nil {
-		// 		return err
-		// 	}
-		// }
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
-			if e.includeTests || (!e.includeTests && !strings.HasSuffix(entry.Name(), "_test.go")) {
-				fileComments, err := e.extractCommentsFromFile(path)
-				if err != nil {
-					return err
-				}
-				if len(fileComments) > 0 {
-					fmt.Printf("%d comments found in `%s`\n", len(fileComments), path)
-					comments = append(comments, fileComments...)
-					e.resetState()
-				}
+		rel, err := filepath.Rel(e.projectPath, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if entry.IsDir() {
+			if excludes.Match(rel, true) {
+				e.logf("Skipping %s\n", path)
+				return filepath.SkipDir
 			}
+			return nil
 		}
-		return nil
 
+		if !strings.HasSuffix(entry.Name(), ".go") || (!e.includeTests && strings.HasSuffix(entry.Name(), "_test.go")) {
+			return nil
+		}
+		if excludes.Match(rel, false) || (len(e.Include) > 0 && !includes.MatchAny(rel)) {
+			return nil
+		}
+		if isGenerated(path) {
+			e.logf("Skipping generated file %s\n", path)
+			return nil
+		}
+		files = append(files, path)
+		return nil
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return comments, nil
+	return files, nil
+}
+
+// Reads reports whether Files would return the file, without walking the whole project
+func (e *Lexer) Reads(filePath string) (bool, error) {
+	rel, err := filepath.Rel(e.projectPath, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, nil
+	}
+	rel = filepath.ToSlash(rel)
+
+	name := filepath.Base(filePath)
+	if !strings.HasSuffix(name, ".go") || (!e.includeTests && strings.HasSuffix(name, "_test.go")) {
+		return false, nil
+	}
+
+	excludes, includes, err := e.matchers()
+	if err != nil {
+		return false, err
+	}
+	if excludes.MatchAny(rel) || (len(e.Include) > 0 && !includes.MatchAny(rel)) {
+		return false, nil
+	}
+	return !isGenerated(filePath), nil
+}
+
+func (e *Lexer) matchers() (*ignore.Matcher, *ignore.Matcher, error) {
+	patterns, err := ignore.ReadFile(filepath.Join(e.projectPath, ignore.FileName))
+	if err != nil {
+		return nil, nil, err
+	}
+	excludes := ignore.New(append(append(append([]string{}, defaultExcludes...), e.Exclude...), patterns...)...)
+	return excludes, ignore.New(e.Include...), nil
+}
+
+// Generated files carry a `// Code generated ... DO NOT EDIT.` comment before the package clause
+func isGenerated(filePath string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), filePath, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false
+	}
+	return ast.IsGenerated(f)
 }
 
 func (e *Lexer) extractGoMod(filePath string) (string, error) {
@@ -90,199 +232,152 @@ func (e *Lexer) extractGoMod(filePath string) (string, error) {
 	return "", fmt.Errorf("module line not found in go.mod file")
 }
 
-func (e *Lexer) extractCommentsFromFile(filePath string) ([]types.CommentBlock, error) {
-	var comments []types.CommentBlock
-	fmt.Printf("\033[32mReading comments from %s\n\033[0m", filePath)
-
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	content, err := e.readFileContent(file)
+// Lexes a file unless its content is cached
+func (e *Lexer) lexFile(filePath string) (*fileLexer, error) {
+	src, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %v", filePath, err)
 	}
-	e.src = content
 
-	pkgName, err := e.extractPkgName()
-	if err != nil {
-		return comments, fmt.Errorf("error receiving package name")
+	f := &fileLexer{path: filePath}
+	if e.Cache != nil {
+		var ok bool
+		if f.comments, f.diagnostics, ok = e.Cache.Lookup(filePath, src); ok {
+			return f, nil
+		}
 	}
 
-	e.readPosition = 0
-	e.readChar()
-
-	for !e.isAtEnd() {
-		if e.isGoDocComment() {
-			comment, err := e.extractBlockComment(filePath, pkgName)
-			if err != nil {
-				return comments, err
-			}
-			if !isEmptyComment(comment) {
-				comments = append(comments, comment)
-			}
-		} else {
-			e.readChar()
-		}
+	f.extract(src)
+	if e.Cache != nil {
+		e.Cache.Store(filePath, src, f.comments, f.diagnostics)
 	}
+	return f, nil
+}
 
-	return comments, nil
+// ExtractSource lexes the content of a single file, eg. an unsaved editor buffer, the same way
+// files on disk are lexed
+func ExtractSource(filePath string, src []byte) ([]types.CommentBlock, []types.Diagnostic) {
+	f := &fileLexer{path: filePath}
+	f.extract(src)
+	return f.comments, f.diagnostics
 }
 
-func (e *Lexer) readFileContent(file *os.File) (string, error) {
-	var sb strings.Builder
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		sb.WriteString(scanner.Text() + "\n")
-	}
-	if err := scanner.Err(); err != nil {
-		return "", err
-	}
-	return sb.String(), nil
+// State of lexing a single file, so files can be lexed concurrently
+type fileLexer struct {
+	path        string
+	comments    []types.CommentBlock
+	diagnostics []types.Diagnostic
 }
 
-func (e *Lexer) extractPkgName() (string, error) {
-	e.readChar()
-	e.skipWhitespace()
+// Scans the file with the Go tokenizer so `/***` inside string or rune literals is never
+// mistaken for a comment block
+func (f *fileLexer) extract(src []byte) {
+	filePath := f.path
 
-	var pkgName strings.Builder
+	fset := token.NewFileSet()
+	file := fset.AddFile(filePath, -1, len(src))
 
-	for e.ch != 0 {
-		if e.ch == '/' && e.peekChar(0) == '/' {
-			e.skipLineComment()
-			continue
+	var s scanner.Scanner
+	s.Init(file, src, func(pos token.Position, msg string) {
+		// Syntax errors are left to the Go compiler, only unterminated comments concern DocMate
+		if msg == "comment not terminated" {
+			f.addDiagnostic(position(filePath, pos), types.SeverityError, types.CodeUnterminated, "comment block is never closed with `*/`")
 		}
-		if e.ch == '/' && e.peekChar(0) == '*' {
-			e.skipBlockComment()
-			continue
-		}
-
-		// Look for the 'package' keyword
-		if e.ch == 'p' && strings.HasPrefix(e.src[e.position:], "package ") {
-			// Move the position past 'package '
-			e.position += len("package ")
-			e.readPosition = e.position
-			e.readChar()
+	}, scanner.ScanComments)
 
-			e.skipWhitespace()
+	// The package clause usually follows the file's comment blocks, so the package name is
+	// assigned once the whole file has been scanned
+	pkgName := ""
+	expectPkgName := false
 
-			// Extract the entire package name
-			for e.ch != ' ' && e.ch != '\n' && e.ch != '\r' && e.ch != 0 {
-				pkgName.WriteByte(e.ch)
-				e.readChar()
-			}
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
 			break
 		}
 
-		e.readChar()
+		switch {
+		case tok == token.COMMENT:
+			if IsBlockComment(lit) {
+				comment := f.extractBlockComment(fset.Position(pos), lit)
+				if !isEmptyComment(comment) {
+					f.comments = append(f.comments, comment)
+				}
+			}
+			continue
+		case tok == token.PACKAGE:
+			expectPkgName = pkgName == ""
+			continue
+		case tok == token.IDENT && expectPkgName:
+			pkgName = lit
+		}
+		expectPkgName = false
 	}
 
-	packageName := pkgName.String()
-
 	// Default to "main" if no valid package name is found
-	if strings.TrimSpace(packageName) == "" {
-		packageName = "main"
+	if pkgName == "" {
+		pkgName = "main"
+	}
+	for i := range f.comments {
+		f.comments[i].Package = pkgName
 	}
-
-	return packageName, nil
 }
 
-
-func (e *Lexer) extractBlockComment(filePath, pkgName string) (types.CommentBlock, error) {
+// Splits the comment literal into trimmed lines, recording where each line starts
+func (f *fileLexer) extractBlockComment(start token.Position, lit string) types.CommentBlock {
+	filePath := f.path
 	var lines []string
-	e.advanceBy(4)
+	var positions []types.Position
 
-	for !e.isAtEnd() {
-		e.skipWhitespace()
-
-		if e.ch == '*' && e.peekChar(0) == '/' {
-			e.advanceBy(2)
-			break
-		}
-
-		var sb strings.Builder
-		for e.ch != '\n' && e.ch != 0 {
-			sb.WriteByte(e.ch)
-			e.readChar()
-		}
+	body := strings.TrimSuffix(strings.TrimPrefix(lit, "/***"), "*/")
+	line, column := start.Line, start.Column+len("/***")
 
-		line := strings.TrimSpace(sb.String())
-		if len(line) > 0 {
-			lines = append(lines, line)
+	for _, raw := range strings.Split(body, "\n") {
+		text := strings.TrimSpace(raw)
+		if len(text) > 0 {
+			indent := len(raw) - len(strings.TrimLeft(raw, " \t\r"))
+			lines = append(lines, text)
+			positions = append(positions, types.Position{Filepath: filePath, Line: line, Column: column + indent})
 		}
 
-		e.readChar()
+		line++
+		column = 1
 	}
 
 	if len(lines) == 0 {
-		return types.CommentBlock{}, fmt.Errorf("empty comment found in %s", filePath)
+		f.addDiagnostic(position(filePath, start), types.SeverityWarning, types.CodeEmptyComment, "empty comment block")
+		return types.CommentBlock{}
 	}
 
 	return types.CommentBlock{
 		Filepath: filePath,
-		Package:  pkgName,
 		Text:     lines,
-	}, nil
-}
-
-func (e *Lexer) resetState() {
-	e.position = 0
-	e.readPosition = 0
-}
-
-func (e *Lexer) peekChar(ahead int) byte {
-	if e.readPosition+ahead >= len(e.src) {
-		return 0
-	}
-	return e.src[e.readPosition]
-}
-
-func (e *Lexer) readChar() {
-	if e.readPosition >= len(e.src) {
-		e.ch = 0
-	} else {
-		e.ch = e.src[e.readPosition]
+		Line:     start.Line,
+		Column:   start.Column,
+		TextPos:  positions,
 	}
-	e.position = e.readPosition
-	e.readPosition += 1
 }
 
-func (e *Lexer) advanceBy(n int) {
-	for i := 0; i < n; i++ {
-		e.readChar()
-	}
+func (f *fileLexer) addDiagnostic(pos types.Position, severity types.Severity, code, message string) {
+	f.diagnostics = append(f.diagnostics, types.Diagnostic{Pos: pos, Severity: severity, Code: code, Message: message})
 }
 
-func (e *Lexer) skipWhitespace() {
-	for e.ch == ' ' || e.ch == '\t' || e.ch == '\n' || e.ch == '\r' {
-		e.readChar()
+func (e *Lexer) logf(format string, args ...any) {
+	if e.Verbose {
+		fmt.Printf(format, args...)
 	}
 }
 
-func (e *Lexer) isAtEnd() bool {
-	return e.readPosition >= len(e.src)
-}
-
-func (e *Lexer) skipLineComment() {
-	for e.ch != '\n' && e.ch != 0 {
-		e.readChar()
-	}
-}
-
-func (e *Lexer) skipBlockComment() {
-	e.readChar()
-	e.readChar()
-	for !(e.ch == '*' && e.peekChar(0) == '/') && e.ch != 0 {
-		e.readChar()
-	}
-	e.readChar()
-	e.readChar()
+func position(filePath string, pos token.Position) types.Position {
+	return types.Position{Filepath: filePath, Line: pos.Line, Column: pos.Column}
 }
 
-func (e *Lexer) isGoDocComment() bool {
-	return e.ch == '/' && e.peekChar(0) == '*' && e.peekChar(1) == '*' && e.peekChar(2) == '*'
+// IsBlockComment reports whether the comment literal is a DocMate block, which opens with
+// three asterisks followed by whitespace. Banners such as `/*****` and the empty `/***/` are
+// ordinary comments
+func IsBlockComment(lit string) bool {
+	rest, ok := strings.CutPrefix(lit, "/***")
+	return ok && rest != "" && unicode.IsSpace(rune(rest[0]))
 }
 
 func isEmptyComment(comment types.CommentBlock) bool {
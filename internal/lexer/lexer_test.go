@@ -0,0 +1,321 @@
+package lexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+const lexSource = `// Package comments may come first
+package demo
+
+var raw = ` + "`/*** not a block */`" + `
+var quoted = "/*** not a block either */"
+
+/***
+	-- FUNC
+	@func Add
+  @desc Adds two numbers
+*/
+func Add(a, b int) int { return a + b }
+`
+
+func TestExtractSource(t *testing.T) {
+	comments, diagnostics := ExtractSource("demo.go", []byte(lexSource))
+	if len(diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics %v", diagnostics)
+	}
+	if len(comments) != 1 {
+		t.Fatalf("got %d comment blocks, want only the one outside the string literals: %+v", len(comments), comments)
+	}
+
+	comment := comments[0]
+	if comment.Package != "demo" {
+		t.Errorf("got package %q, want the package clause's `demo`", comment.Package)
+	}
+	if comment.Line != 7 || comment.Column != 1 {
+		t.Errorf("block starts at %d:%d, want 7:1", comment.Line, comment.Column)
+	}
+
+	wantText := []string{"-- FUNC", "@func Add", "@desc Adds two numbers"}
+	wantPos := []types.Position{
+		{Filepath: "demo.go", Line: 8, Column: 2},
+		{Filepath: "demo.go", Line: 9, Column: 2},
+		{Filepath: "demo.go", Line: 10, Column: 3},
+	}
+	if strings.Join(comment.Text, "|") != strings.Join(wantText, "|") {
+		t.Errorf("got text %q, want %q", comment.Text, wantText)
+	}
+	if !slices.Equal(comment.TextPos, wantPos) {
+		t.Errorf("got positions %v, want %v", comment.TextPos, wantPos)
+	}
+}
+
+func TestExtractSourceOnSameLine(t *testing.T) {
+	// Text following `/***` starts after it on the block's first line
+	comments, _ := ExtractSource("demo.go", []byte("package demo\n\n\t/*** -- VAR\n@var X */\nvar X = 1\n"))
+	if len(comments) != 1 {
+		t.Fatalf("got %d comment blocks, want 1", len(comments))
+	}
+	want := []types.Position{{Filepath: "demo.go", Line: 3, Column: 7}, {Filepath: "demo.go", Line: 4, Column: 1}}
+	if !slices.Equal(comments[0].TextPos, want) {
+		t.Errorf("got positions %v, want %v", comments[0].TextPos, want)
+	}
+}
+
+func TestIsBlockComment(t *testing.T) {
+	tests := []struct {
+		lit  string
+		want bool
+	}{
+		{lit: "/***\n-- VAR\n@var X\n*/", want: true},
+		{lit: "/*** -- VAR\n@var X */", want: true},
+		{lit: "/***\t-- VAR */", want: true},
+		{lit: "/***\r\n-- VAR\r\n*/", want: true},
+		{lit: "/***/"},
+		{lit: "/*****/"},
+		{lit: "/****************\n * Banner\n ****************/"},
+		{lit: "/***-- VAR */"},
+		{lit: "/** Ordinary */"},
+		{lit: "// /*** Line comment"},
+	}
+
+	for _, test := range tests {
+		if got := IsBlockComment(test.lit); got != test.want {
+			t.Errorf("%q: got %t, want %t", test.lit, got, test.want)
+		}
+	}
+
+	// Banners around a block are left alone
+	src := "package demo\n\n/**********\n * Helpers\n **********/\n\n/***\n-- VAR\n@var X\n*/\nvar X = 1\n"
+	comments, diagnostics := ExtractSource("demo.go", []byte(src))
+	if len(comments) != 1 || comments[0].Line != 7 || len(diagnostics) > 0 {
+		t.Errorf("got blocks %+v and diagnostics %v, want the block at line 7", comments, diagnostics)
+	}
+}
+
+func TestExtractSourceUnterminated(t *testing.T) {
+	_, diagnostics := ExtractSource("demo.go", []byte("package demo\n\n/***\n\t-- FUNC\n\t@func Add\n"))
+	if len(diagnostics) != 1 || diagnostics[0].Code != types.CodeUnterminated || diagnostics[0].Pos.Line != 3 {
+		t.Errorf("got diagnostics %v, want DM011 at line 3", diagnostics)
+	}
+}
+
+func TestExtractSourceWithoutPackage(t *testing.T) {
+	comments, _ := ExtractSource("demo.go", []byte("/***\n-- VAR\n@var X\n*/\n"))
+	if len(comments) != 1 || comments[0].Package != "main" {
+		t.Errorf("got %+v, want one block in package `main`", comments)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	tree := map[string]string{
+		"main.go":                 "package main\n",
+		"internal/a.go":           "package internal\n",
+		"internal/a_test.go":      "package internal\n",
+		"internal/api.pb.go":      "package internal\n",
+		"internal/gen.go":         "// Code generated by stringer; DO NOT EDIT.\n\npackage internal\n",
+		"internal/notes.txt":      "not Go",
+		"vendor/dep/dep.go":       "package dep\n",
+		".git/hooks/hook.go":      "package hooks\n",
+		"testdata/fixture.go":     "package fixture\n",
+		"testdata/bad/bad.go":     "package bad\n",
+		"tools/tool.go":           "package tools\n",
+		"tools/nested/nested.go":  "package nested\n",
+		"cmd/docmate/main.go":     "package main\n",
+		"cmd/docmate/generate.go": "// Code generated by go generate. DO NOT EDIT.\npackage main\n",
+	}
+	for name, content := range tree {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		ignoreFile   string
+		includeTests bool
+		include      []string
+		exclude      []string
+		want         []string
+	}{
+		{
+			name: "defaults",
+			want: []string{"cmd/docmate/main.go", "internal/a.go", "internal/api.pb.go", "main.go", "tools/nested/nested.go", "tools/tool.go"},
+		},
+		{
+			name:         "tests",
+			includeTests: true,
+			want:         []string{"cmd/docmate/main.go", "internal/a.go", "internal/a_test.go", "internal/api.pb.go", "main.go", "tools/nested/nested.go", "tools/tool.go"},
+		},
+		{
+			name:       "ignore file re-including testdata",
+			ignoreFile: "*.pb.go\n!testdata/\ntestdata/bad/\n",
+			want:       []string{"cmd/docmate/main.go", "internal/a.go", "main.go", "testdata/fixture.go", "tools/nested/nested.go", "tools/tool.go"},
+		},
+		{
+			name:    "exclude",
+			exclude: []string{"tools/", "/main.go"},
+			want:    []string{"cmd/docmate/main.go", "internal/a.go", "internal/api.pb.go"},
+		},
+		{
+			name:    "include",
+			include: []string{"internal/**", "tools/*.go"},
+			want:    []string{"internal/a.go", "internal/api.pb.go", "tools/tool.go"},
+		},
+	}
+
+	for _, test := range tests {
+		ignorePath := filepath.Join(dir, ".docmateignore")
+		os.Remove(ignorePath)
+		if test.ignoreFile != "" {
+			if err := os.WriteFile(ignorePath, []byte(test.ignoreFile), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		e := New(test.includeTests, dir)
+		e.Include, e.Exclude = test.include, test.exclude
+		files, err := e.Files()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		var got []string
+		for _, file := range files {
+			rel, _ := filepath.Rel(dir, file)
+			got = append(got, filepath.ToSlash(rel))
+			// Reads agrees with the walk for every file it returns
+			if reads, err := e.Reads(file); err != nil || !reads {
+				t.Errorf("%s: Reads(%s) = %t, %v, want true", test.name, rel, reads, err)
+			}
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// Records the files the workers read, every file read is looked up before it's lexed
+type lookupRecorder struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (r *lookupRecorder) Lookup(path string, src []byte) ([]types.CommentBlock, []types.Diagnostic, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paths = append(r.paths, filepath.Base(path))
+	return nil, nil, false
+}
+
+func (r *lookupRecorder) Store(path string, src []byte, comments []types.CommentBlock, diagnostics []types.Diagnostic) {
+}
+
+func TestExtractCommentsCancels(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("package demo\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	// A directory can't be read as a file, even by root
+	unreadable := filepath.Join(dir, "bad.go")
+	if err := os.Mkdir(unreadable, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files = slices.Insert(files, 1, unreadable)
+
+	recorder := &lookupRecorder{}
+	e := New(false, dir)
+	e.Workers = 1
+	e.Cache = recorder
+	comments, err := e.ExtractCommentsFrom(context.Background(), files)
+	if err == nil || !strings.Contains(err.Error(), "failed to read file "+unreadable) {
+		t.Fatalf("got %d blocks and error %v, want the unreadable file reported", len(comments), err)
+	}
+	// With a single worker nothing after the unreadable file is read
+	if want := []string{"a.go"}; !slices.Equal(recorder.paths, want) {
+		t.Errorf("got files read %v, want %v", recorder.paths, want)
+	}
+}
+
+// Writes a project of packages*files Go files, each holding a few DocMate blocks among
+// ordinary code
+func syntheticTree(b *testing.B, packages, files int) string {
+	b.Helper()
+	root := b.TempDir()
+
+	for p := 0; p < packages; p++ {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%d", p))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			b.Fatal(err)
+		}
+
+		for f := 0; f < files; f++ {
+			var src strings.Builder
+			fmt.Fprintf(&src, "package pkg%d\n\n", p)
+			for fn := 0; fn < 20; fn++ {
+				fmt.Fprintf(&src, "/***\n\t-- FUNC\n\t@func Func%d\n\t@desc Does thing %d\n\t@param a: The first value\n\t@ret The sum\n*/\n", fn, fn)
+				fmt.Fprintf(&src, "func Func%d_%d(a int) int {\n\ts := \"/*** not a block */\"\n\t_ = s\n", f, fn)
+				for line := 0; line < 30; line++ {
+					fmt.Fprintf(&src, "\ta += %d // step %d\n", line, line)
+				}
+				src.WriteString("\treturn a\n}\n\n")
+			}
+
+			path := filepath.Join(dir, fmt.Sprintf("file%d.go", f))
+			if err := os.WriteFile(path, []byte(src.String()), 0644); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	return root
+}
+
+// Compares lexing one file at a time with the worker pool, eg.
+//
+//	go test ./internal/lexer -bench ExtractComments -benchmem
+func BenchmarkExtractComments(b *testing.B) {
+	root := syntheticTree(b, 20, 50)
+
+	for _, workers := range []int{1, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			files, err := New(false, root).Files()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Diagnostics accumulate on the lexer, so each run gets its own
+				lexer := New(false, root)
+				lexer.Workers = workers
+				comments, err := lexer.ExtractCommentsFrom(context.Background(), files)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(comments) != len(files)*20 {
+					b.Fatalf("got %d comment blocks, want %d", len(comments), len(files)*20)
+				}
+			}
+		})
+	}
+}
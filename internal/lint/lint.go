@@ -0,0 +1,170 @@
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/ajtroup1/DocMate/internal/resolver"
+	"github.com/ajtroup1/DocMate/internal/schema"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Check compares each bound block with its declaration and reports documentation that
+// no longer matches the code
+func Check(bindings []resolver.Binding) []types.Diagnostic {
+	var diagnostics []types.Diagnostic
+
+	for _, binding := range bindings {
+		switch decl := binding.Decl.(type) {
+		case *ast.FuncDecl:
+			diagnostics = append(diagnostics, checkFunction(binding, decl)...)
+		case *ast.TypeSpec:
+			diagnostics = append(diagnostics, checkType(binding, decl)...)
+		case *ast.ValueSpec:
+			diagnostics = append(diagnostics, checkVariable(binding, decl)...)
+		}
+	}
+
+	return diagnostics
+}
+
+func checkFunction(binding resolver.Binding, fn *ast.FuncDecl) []types.Diagnostic {
+	var diagnostics []types.Diagnostic
+
+	params := fieldNames(fn.Type.Params, false)
+	for _, param := range binding.Doc.Params {
+		if !contains(params, param.Name) {
+			diagnostics = append(diagnostics, diagnostic(param.Pos, types.SeverityError, types.CodeStaleTag,
+				"`@%s %s` does not match any parameter of `%s`", schema.TagParam, param.Name, fn.Name.Name))
+		}
+	}
+
+	results := 0
+	if fn.Type.Results != nil {
+		results = fn.Type.Results.NumFields()
+	}
+	if len(binding.Doc.Returns) > results {
+		for _, ret := range binding.Doc.Returns[results:] {
+			diagnostics = append(diagnostics, diagnostic(ret.Pos, types.SeverityError, types.CodeStaleTag,
+				"`@%s` has no matching result, `%s` returns %d value(s)", schema.TagReturn, fn.Name.Name, results))
+		}
+	}
+
+	if recv := binding.Doc.Receiver; recv != nil {
+		actual := resolver.ReceiverName(fn)
+		if actual == "" {
+			diagnostics = append(diagnostics, diagnostic(recv.Pos, types.SeverityError, types.CodeReceiverMismatch,
+				"`%s` is documented with receiver `%s` but is not a method", fn.Name.Name, recv.Name))
+		} else if actual != recv.Name {
+			diagnostics = append(diagnostics, diagnostic(recv.Pos, types.SeverityError, types.CodeReceiverMismatch,
+				"receiver `%s` does not match `%s`, the receiver of `%s`", recv.Name, actual, fn.Name.Name))
+		}
+	}
+
+	// Only the API of exported functions has to be fully described. A block without any `@param`
+	// leaves them out on purpose, so only blocks describing some of them are reported
+	if ast.IsExported(fn.Name.Name) && len(binding.Doc.Params) > 0 {
+		for _, name := range params {
+			if name != "_" && !hasDesc(binding.Doc.Params, name) {
+				diagnostics = append(diagnostics, diagnostic(binding.Pos, types.SeverityWarning, types.CodeMissingDesc,
+					"parameter `%s` of `%s` has no description", name, fn.Name.Name))
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+func checkType(binding resolver.Binding, spec *ast.TypeSpec) []types.Diagnostic {
+	var diagnostics []types.Diagnostic
+
+	st, isStruct := spec.Type.(*ast.StructType)
+	var fields []string
+	if isStruct {
+		fields = fieldNames(st.Fields, true)
+	}
+
+	for _, field := range binding.Doc.Fields {
+		if !contains(fields, field.Name) {
+			diagnostics = append(diagnostics, diagnostic(field.Pos, types.SeverityError, types.CodeStaleTag,
+				"`@%s %s` does not match any field of `%s`", schema.TagField, field.Name, spec.Name.Name))
+		}
+	}
+
+	// Same as parameters, only blocks describing some of the fields are reported
+	if ast.IsExported(spec.Name.Name) && len(binding.Doc.Fields) > 0 {
+		for _, name := range fields {
+			if ast.IsExported(name) && !hasDesc(binding.Doc.Fields, name) {
+				diagnostics = append(diagnostics, diagnostic(binding.Pos, types.SeverityWarning, types.CodeMissingDesc,
+					"field `%s` of `%s` has no description", name, spec.Name.Name))
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+func checkVariable(binding resolver.Binding, spec *ast.ValueSpec) []types.Diagnostic {
+	// Inferred types can't be compared without type checking the package
+	if binding.Doc.Type == "" || spec.Type == nil {
+		return nil
+	}
+
+	declared := resolver.ExprString(binding.Fset, spec.Type)
+	if normalize(declared) != normalize(binding.Doc.Type) {
+		return []types.Diagnostic{diagnostic(binding.Pos, types.SeverityError, types.CodeTypeMismatch,
+			"`@%s %s` does not match `%s`, the declared type of `%s`", schema.TagType, binding.Doc.Type, declared, binding.Name)}
+	}
+
+	return nil
+}
+
+// Names of every field in the list, embedded struct fields are named after their type
+func fieldNames(list *ast.FieldList, isStruct bool) []string {
+	var names []string
+	if list == nil {
+		return names
+	}
+
+	for _, field := range list.List {
+		if len(field.Names) == 0 {
+			if name := resolver.EmbeddedName(field.Type); isStruct && name != "" {
+				names = append(names, name)
+			}
+			continue
+		}
+		for _, ident := range field.Names {
+			names = append(names, ident.Name)
+		}
+	}
+
+	return names
+}
+
+func hasDesc(vars []types.Variable, name string) bool {
+	for _, v := range vars {
+		if v.Name == name && v.Desc != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Whitespace doesn't change a type, eg. `map[string] int` and `map[string]int`
+func normalize(typ string) string {
+	return strings.Join(strings.Fields(typ), "")
+}
+
+func diagnostic(pos types.Position, severity types.Severity, code, format string, args ...any) types.Diagnostic {
+	return types.Diagnostic{Pos: pos, Severity: severity, Code: code, Message: fmt.Sprintf(format, args...)}
+}
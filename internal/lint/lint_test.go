@@ -0,0 +1,93 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/lexer"
+	"github.com/ajtroup1/DocMate/internal/parser"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string // Declarations and blocks following `package demo`
+		want   []string
+	}{
+		{
+			name:   "param that isn't declared",
+			source: "/***\n-- FUNC\n@func add\n@param a (int): A\n@param z (int): Z\n*/\nfunc add(a int) {}",
+			want:   []string{"DM014 `@param z` does not match any parameter of `add`"},
+		},
+		{
+			name:   "return beyond the results",
+			source: "/***\n-- FUNC\n@func add\n@ret (int): Sum\n*/\nfunc add() {}",
+			want:   []string{"DM014 `@return` has no matching result, `add` returns 0 value(s)"},
+		},
+		{
+			name:   "field that isn't declared",
+			source: "/***\n-- TYPE\n@type pair\n@field z (int): Z\n*/\ntype pair struct{ a int }",
+			want:   []string{"DM014 `@field z` does not match any field of `pair`"},
+		},
+		{
+			name:   "exported function with an undescribed param",
+			source: "/***\n-- FUNC\n@func Add\n@param a (int): A\n*/\nfunc Add(a, b int) {}",
+			want:   []string{"DM015 parameter `b` of `Add` has no description"},
+		},
+		{
+			name:   "exported function without any params described",
+			source: "/***\n-- FUNC\n@func Add\n@desc Adds\n*/\nfunc Add(a, b int) {}",
+		},
+		{
+			name:   "exported type with an undescribed field",
+			source: "/***\n-- TYPE\n@type Pair\n@field A: First\n*/\ntype Pair struct{ A, B int; c int }",
+			want:   []string{"DM015 field `B` of `Pair` has no description"},
+		},
+		{
+			name:   "exported type without any fields described",
+			source: "/***\n-- TYPE\n@type Pair\n@desc Two values\n*/\ntype Pair struct{ A, B int }",
+		},
+		{
+			name:   "variable type that doesn't match",
+			source: "/***\n-- VAR\n@var limit\n@type string\n*/\nvar limit int",
+			want:   []string{"DM016 `@type string` does not match `int`, the declared type of `limit`"},
+		},
+		{
+			name:   "variable type that only differs in spacing",
+			source: "/***\n-- VAR\n@var counts\n@type map[string] int\n*/\nvar counts map[string]int",
+		},
+		{
+			name:   "receiver that doesn't match",
+			source: "/***\n-- FUNC\n@func close\n@rec Conn\n*/\nfunc (p *Pool) close() {}\n\ntype Pool struct{}",
+			want:   []string{"DM017 receiver `Conn` does not match `Pool`, the receiver of `close`"},
+		},
+		{
+			name:   "receiver on a function",
+			source: "/***\n-- FUNC\n@func close\n@rec Conn\n*/\nfunc close() {}",
+			want:   []string{"DM017 `close` is documented with receiver `Conn` but is not a method"},
+		},
+		{
+			name:   "documentation that matches",
+			source: "/***\n-- FUNC\n@func Div\n@param a (int): A\n@param b (int): B\n@ret (int): Quotient\n@ret (error): When b is zero\n*/\nfunc Div(a, b int) (int, error) { return a / b, nil }",
+		},
+	}
+
+	for _, test := range tests {
+		src := []byte("package demo\n\n" + test.source + "\n")
+		comments, diagnostics := lexer.ExtractSource("demo.go", src)
+		p := parser.New(comments, false)
+		p.Sources = map[string][]byte{"demo.go": src}
+		p.ParseComments()
+		if diagnostics = append(diagnostics, p.Diagnostics...); len(diagnostics) > 0 {
+			t.Fatalf("%s: unexpected diagnostics while parsing %v", test.name, diagnostics)
+		}
+
+		var got []string
+		for _, d := range Check(p.Bindings) {
+			got = append(got, d.Code+" "+d.Message)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
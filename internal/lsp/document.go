@@ -0,0 +1,144 @@
+package lsp
+
+import (
+	"go/scanner"
+	"go/token"
+	"strings"
+
+	"github.com/ajtroup1/DocMate/internal/lexer"
+	"github.com/ajtroup1/DocMate/internal/lint"
+	"github.com/ajtroup1/DocMate/internal/parser"
+	"github.com/ajtroup1/DocMate/internal/resolver"
+	"github.com/ajtroup1/DocMate/internal/schema"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// An open document, analyzed on every change the same way the CLI analyzes files on disk
+type document struct {
+	uri         string
+	path        string
+	text        string
+	lines       []string
+	lineStarts  []int // Byte offset of each line
+	blocks      []span
+	comments    []types.CommentBlock
+	packages    []types.Package
+	bindings    []resolver.Binding
+	diagnostics []types.Diagnostic
+	headers     []schema.Header // Along with the custom tags from the settings
+	settings    *types.Settings
+}
+
+// Byte offsets of a `/***` block, end is past the closing `*/` or the end of an unterminated block
+type span struct {
+	start, end int
+}
+
+func newDocument(uri, text string, settings *types.Settings) *document {
+	d := &document{uri: uri, path: uriToPath(uri), text: text, lines: strings.Split(text, "\n"), settings: settings}
+	d.headers, _ = schema.Extend(settings.CustomTags)
+	offset := 0
+	for _, line := range d.lines {
+		d.lineStarts = append(d.lineStarts, offset)
+		offset += len(line) + 1
+	}
+	d.blocks = findBlocks(d.path, []byte(text))
+
+	var lexDiagnostics []types.Diagnostic
+	d.comments, lexDiagnostics = lexer.ExtractSource(d.path, []byte(text))
+
+	p := parser.New(d.comments, settings.CapitalizeItems)
+	p.Sources = map[string][]byte{d.path: []byte(text)}
+	p.Headers = d.headers
+	p.ParseComments()
+	d.packages, d.bindings = p.Packages, p.Bindings
+
+	// Same order as `docmate lint`
+	d.diagnostics = append(append(lexDiagnostics, p.Diagnostics...), lint.Check(p.Bindings)...)
+	return d
+}
+
+// Finds the `/***` blocks with the Go scanner, as the lexer does, so strings never count
+func findBlocks(path string, src []byte) []span {
+	fset := token.NewFileSet()
+	file := fset.AddFile(path, -1, len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	var blocks []span
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.COMMENT && lexer.IsBlockComment(lit) {
+			start := file.Offset(pos)
+			blocks = append(blocks, span{start: start, end: start + len(lit)})
+		}
+	}
+	return blocks
+}
+
+// Returns the block holding the offset, between its `/***` and `*/`
+func (d *document) blockAt(offset int) (span, bool) {
+	for _, b := range d.blocks {
+		closed := strings.HasSuffix(d.text[b.start:b.end], "*/")
+		end := b.end
+		if closed {
+			end -= len("*/")
+		}
+		if offset >= b.start+len("/***") && offset <= end {
+			return b, true
+		}
+	}
+	return span{}, false
+}
+
+// The line a block starts on, counted from 1 like comment block positions
+func (d *document) lineOf(offset int) int {
+	line := 0
+	for i, start := range d.lineStarts {
+		if start > offset {
+			break
+		}
+		line = i
+	}
+	return line + 1
+}
+
+// Byte offset of an LSP position
+func (d *document) offset(pos position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	return d.lineStarts[pos.Line] + toOffset(d.lines[pos.Line], pos.Character)
+}
+
+// LSP position of a line and column counted from 1, the column in bytes
+func (d *document) position(line, column int) position {
+	if line < 1 {
+		return position{}
+	}
+	if line > len(d.lines) {
+		return position{Line: len(d.lines) - 1}
+	}
+	return position{Line: line - 1, Character: toCharacter(d.lines[line-1], column)}
+}
+
+// Range of a diagnostic, from its position to the end of the line's text
+func (d *document) diagnosticRange(pos types.Position) textRange {
+	start := d.position(pos.Line, pos.Column)
+	end := start
+	if pos.Line >= 1 && pos.Line <= len(d.lines) {
+		line := strings.TrimRight(d.lines[pos.Line-1], " \t\r")
+		end = position{Line: pos.Line - 1, Character: utf16Len(line)}
+	}
+	if end.Character <= start.Character {
+		end.Character = start.Character + 1
+	}
+	return textRange{Start: start, End: end}
+}
@@ -0,0 +1,398 @@
+package lsp
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/ajtroup1/DocMate/internal/generator"
+	"github.com/ajtroup1/DocMate/internal/resolver"
+	"github.com/ajtroup1/DocMate/internal/schema"
+)
+
+// Completes headers after `--` and tags after `@` inside a block, offering only the tags the
+// block's header accepts
+func (d *document) completion(pos position) []completionItem {
+	block, ok := d.blockAt(d.offset(pos))
+	if !ok || pos.Line >= len(d.lines) {
+		return nil
+	}
+	line := d.lines[pos.Line]
+	col := toOffset(line, pos.Character)
+	prefix := line[:col]
+	if d.lineOf(block.start) == pos.Line+1 {
+		prefix = prefix[strings.Index(prefix, "/***")+len("/***"):]
+	}
+	trimmed := strings.TrimLeft(prefix, " \t")
+
+	if rest, ok := strings.CutPrefix(trimmed, "--"); ok {
+		word := strings.TrimLeft(rest, " \t")
+		if strings.ContainsAny(word, " \t") {
+			return nil
+		}
+		return completeTags(headerTags(), word, pos, col-len(word), line, "", strings.ToUpper)
+	}
+
+	word, ok := strings.CutPrefix(trimmed, "@")
+	if !ok || strings.ContainsAny(word, " \t") {
+		return nil
+	}
+	tags := d.tagsAt(block, pos)
+	return completeTags(tags, word, pos, col-len(trimmed), line, "@", strings.ToLower)
+}
+
+// Tags accepted at the position, depending on the block's header and whether the position is
+// inside a `@dep { ... }` block
+func (d *document) tagsAt(block span, pos position) []schema.Tag {
+	var header *schema.Header
+	depth := 0
+	// Text may follow `/***` on the block's first line, eg. `/*** @func Add`
+	start := block.start + len("/***")
+	before := d.text[start:max(d.offset(pos), start)]
+	for _, text := range strings.Split(before, "\n") {
+		text = strings.TrimSpace(text)
+		switch {
+		case header == nil && strings.HasPrefix(text, "--"):
+			header, _ = schema.Find(d.headers, strings.TrimPrefix(text, "--"))
+		case strings.HasPrefix(text, "@") && strings.HasSuffix(text, "{"):
+			depth++
+		case text == "}" && depth > 0:
+			depth--
+		}
+	}
+
+	if depth > 0 {
+		return schema.DependencyTags
+	}
+	if header == nil {
+		return nil
+	}
+	return header.Tags
+}
+
+// Headers are completed and described like tags
+func headerTags() []schema.Tag {
+	var tags []schema.Tag
+	for _, h := range schema.Headers {
+		tags = append(tags, schema.Tag{Name: h.Name, Aliases: h.Aliases, Doc: h.Doc})
+	}
+	return tags
+}
+
+// Offers every name and alias starting with word, replacing from start to the cursor
+func completeTags(tags []schema.Tag, word string, pos position, start int, line, sigil string, fold func(string) string) []completionItem {
+	editRange := textRange{Start: position{Line: pos.Line, Character: toCharacter(line, start+1)}, End: pos}
+	word = fold(word)
+
+	var items []completionItem
+	for _, t := range tags {
+		for i, name := range t.Names() {
+			// Custom tags keep the case they were declared with, eg. `@Owner`
+			if !strings.HasPrefix(fold(name), word) {
+				continue
+			}
+			detail := "Alias of " + sigil + t.Name
+			if i == 0 {
+				detail = ""
+				if len(t.Aliases) > 0 {
+					detail = "Also " + sigil + strings.Join(t.Aliases, ", "+sigil)
+				}
+			}
+			items = append(items, completionItem{
+				Label:         sigil + name,
+				Kind:          completionKeyword,
+				Detail:        detail,
+				Documentation: &markupContent{Kind: "markdown", Value: tagDoc(&t)},
+				TextEdit:      &textEdit{Range: editRange, NewText: sigil + name},
+			})
+		}
+	}
+	return items
+}
+
+// Describes the tag or header under the cursor, or previews the documentation of the block or
+// declaration under it as it will be rendered
+func (d *document) hover(pos position) *hover {
+	if pos.Line >= len(d.lines) {
+		return nil
+	}
+	offset := d.offset(pos)
+
+	if block, ok := d.blockAt(offset); ok {
+		if text := d.describeWord(block, pos); text != "" {
+			return &hover{Contents: markupContent{Kind: "markdown", Value: text}}
+		}
+		if text := d.preview(d.lineOf(block.start)); text != "" {
+			return &hover{Contents: markupContent{Kind: "markdown", Value: text}}
+		}
+		return nil
+	}
+
+	for _, binding := range d.bindings {
+		ident := declIdent(binding)
+		if ident == nil {
+			continue
+		}
+		start := binding.Fset.Position(ident.Pos())
+		if start.Line != pos.Line+1 {
+			continue
+		}
+		col := toOffset(d.lines[pos.Line], pos.Character) + 1
+		if col < start.Column || col > start.Column+len(ident.Name) {
+			continue
+		}
+		if text := d.preview(binding.Pos.Line); text != "" {
+			r := textRange{Start: d.position(start.Line, start.Column), End: d.position(start.Line, start.Column+len(ident.Name))}
+			return &hover{Contents: markupContent{Kind: "markdown", Value: text}, Range: &r}
+		}
+	}
+	return nil
+}
+
+// Describes the `@tag` or `-- HEADER` under the cursor
+func (d *document) describeWord(block span, pos position) string {
+	line := d.lines[pos.Line]
+	col := toOffset(line, pos.Character)
+	start := strings.LastIndexAny(line[:col], " \t") + 1
+	end := len(line)
+	if i := strings.IndexAny(line[col:], " \t"); i >= 0 {
+		end = col + i
+	}
+	word := line[start:end]
+
+	if name, ok := strings.CutPrefix(word, "@"); ok {
+		t, ok := schema.Lookup(d.tagsAt(block, pos), name)
+		if !ok {
+			return ""
+		}
+		return describeTag(t, "@")
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "--") {
+		t, ok := schema.Lookup(headerTags(), strings.TrimPrefix(word, "--"))
+		if !ok {
+			return ""
+		}
+		return describeTag(t, "-- ")
+	}
+	return ""
+}
+
+func describeTag(t *schema.Tag, sigil string) string {
+	text := fmt.Sprintf("`%s%s`", sigil, t.Name)
+	if len(t.Aliases) > 0 {
+		text += " (also `" + sigil + strings.Join(t.Aliases, "`, `"+sigil) + "`)"
+	}
+	return text + "\n\n" + tagDoc(t)
+}
+
+// Headers have no kind, tags add how their value is written and how often
+func tagDoc(t *schema.Tag) string {
+	if t.Kind == "" {
+		return t.Doc
+	}
+	return fmt.Sprintf("%s\n\n%s, %s", t.Doc, t.Kind.Syntax(), t.Cardinality)
+}
+
+func declIdent(binding resolver.Binding) *ast.Ident {
+	switch decl := binding.Decl.(type) {
+	case *ast.FuncDecl:
+		return decl.Name
+	case *ast.TypeSpec:
+		return decl.Name
+	case *ast.ValueSpec:
+		for _, name := range decl.Names {
+			if name.Name == binding.Name {
+				return name
+			}
+		}
+	case *ast.Ident:
+		return decl
+	}
+	return nil
+}
+
+// Renders the item documented by the block starting at the line with the Markdown template, so
+// the preview matches the generated document
+func (d *document) preview(line int) string {
+	item := d.itemAt(line)
+	if item == nil {
+		return ""
+	}
+	content, err := generator.New(nil, d.settings).RenderMarkdownItem(item)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// The package, file, type, variable or function documented by the block starting at the line
+func (d *document) itemAt(line int) any {
+	for _, pkg := range d.packages {
+		if pkg.Pos.Filepath == d.path && pkg.Pos.Line == line {
+			return pkg
+		}
+		for _, file := range pkg.Files {
+			if file.Pos.Line == line {
+				return file
+			}
+			for _, typ := range file.Types {
+				if typ.Pos.Line == line {
+					return typ
+				}
+			}
+			for _, variable := range file.Vars {
+				if variable.Pos.Line == line {
+					return variable
+				}
+			}
+			for _, function := range file.Funcs {
+				if function.Pos.Line == line {
+					return function
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Offers to insert a stub block above each undocumented declaration in the range
+func (d *document) codeActions(r textRange) []codeAction {
+	fset := token.NewFileSet()
+	f, _ := goparser.ParseFile(fset, d.path, d.text, goparser.ParseComments|goparser.SkipObjectResolution)
+	if f == nil {
+		return nil
+	}
+
+	documented := make(map[int]bool)
+	for _, binding := range d.bindings {
+		if ident := declIdent(binding); ident != nil {
+			documented[binding.Fset.Position(ident.Pos()).Line] = true
+		}
+	}
+
+	var actions []codeAction
+	for _, stub := range stubs(fset, f) {
+		line := fset.Position(stub.ident.Pos()).Line
+		if documented[line] || line-1 < r.Start.Line || line-1 > r.End.Line {
+			continue
+		}
+		at := position{Line: fset.Position(stub.insertAt).Line - 1}
+		edit := textEdit{Range: textRange{Start: at, End: at}, NewText: stub.text}
+		actions = append(actions, codeAction{
+			Title: fmt.Sprintf("Document `%s` with a DocMate block", stub.ident.Name),
+			Kind:  "quickfix",
+			Edit:  &workspaceEdit{Changes: map[string][]textEdit{d.uri: {edit}}},
+		})
+	}
+	return actions
+}
+
+// A block that documents a declaration, inserted above the declaration and its doc comment
+type stub struct {
+	ident    *ast.Ident
+	insertAt token.Pos
+	text     string
+}
+
+func stubs(fset *token.FileSet, f *ast.File) []stub {
+	var stubs []stub
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			stubs = append(stubs, stub{ident: decl.Name, insertAt: docStart(decl.Doc, decl.Pos()), text: funcStub(fset, decl)})
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				// Specs of a grouped declaration are documented one by one inside the group
+				insertAt, doc := decl.Pos(), decl.Doc
+				if decl.Lparen.IsValid() {
+					insertAt = spec.Pos()
+					doc = nil
+				}
+
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if decl.Lparen.IsValid() {
+						doc = spec.Doc
+					}
+					stubs = append(stubs, stub{ident: spec.Name, insertAt: docStart(doc, insertAt), text: typeStub(fset, spec)})
+				case *ast.ValueSpec:
+					if decl.Lparen.IsValid() {
+						doc = spec.Doc
+					}
+					stubs = append(stubs, stub{ident: spec.Names[0], insertAt: docStart(doc, insertAt), text: varStub(fset, spec)})
+				}
+			}
+		}
+	}
+	return stubs
+}
+
+func docStart(doc *ast.CommentGroup, pos token.Pos) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return pos
+}
+
+func funcStub(fset *token.FileSet, fn *ast.FuncDecl) string {
+	lines := []string{"-- FUNC", "@func " + fn.Name.Name}
+	if recv := resolver.ReceiverName(fn); recv != "" {
+		lines = append(lines, "@rec "+recv)
+	}
+	lines = append(lines, "@desc")
+	for _, field := range fieldList(fn.Type.Params) {
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				lines = append(lines, fmt.Sprintf("@param %s (%s):", name.Name, resolver.ExprString(fset, field.Type)))
+			}
+		}
+	}
+	for _, field := range fieldList(fn.Type.Results) {
+		typ := resolver.ExprString(fset, field.Type)
+		if len(field.Names) == 0 {
+			lines = append(lines, fmt.Sprintf("@ret (%s):", typ))
+		}
+		for _, name := range field.Names {
+			lines = append(lines, fmt.Sprintf("@ret %s (%s):", name.Name, typ))
+		}
+	}
+	return blockText(lines)
+}
+
+func typeStub(fset *token.FileSet, spec *ast.TypeSpec) string {
+	lines := []string{"-- TYPE", "@type " + spec.Name.Name, "@desc"}
+	if st, ok := spec.Type.(*ast.StructType); ok {
+		for _, field := range fieldList(st.Fields) {
+			typ := resolver.ExprString(fset, field.Type)
+			if len(field.Names) == 0 {
+				lines = append(lines, fmt.Sprintf("@field %s (%s):", resolver.EmbeddedName(field.Type), typ))
+			}
+			for _, name := range field.Names {
+				lines = append(lines, fmt.Sprintf("@field %s (%s):", name.Name, typ))
+			}
+		}
+	}
+	return blockText(lines)
+}
+
+func varStub(fset *token.FileSet, spec *ast.ValueSpec) string {
+	lines := []string{"-- VAR", "@var " + spec.Names[0].Name}
+	if spec.Type != nil {
+		lines = append(lines, "@type "+resolver.ExprString(fset, spec.Type))
+	}
+	lines = append(lines, "@desc")
+	return blockText(lines)
+}
+
+func fieldList(list *ast.FieldList) []*ast.Field {
+	if list == nil {
+		return nil
+	}
+	return list.List
+}
+
+func blockText(lines []string) string {
+	return "/***\n" + strings.Join(lines, "\n") + "\n*/\n\n"
+}
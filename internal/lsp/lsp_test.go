@@ -0,0 +1,178 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+const source = `package demo
+
+/***
+-- FUNC
+@func Add
+@desc Adds two numbers
+@param a (int): The first number
+@unknown
+*/
+
+func Add(a, b int) int {
+	return a + b
+}
+
+func Sub(a, b int) (diff int) {
+	return a - b
+}
+`
+
+// Runs a session over in-memory pipes, returning every message the server wrote
+func session(t *testing.T, dir string, requests ...map[string]any) []map[string]any {
+	t.Helper()
+	var in bytes.Buffer
+	for _, req := range requests {
+		req["jsonrpc"] = "2.0"
+		body, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	var out bytes.Buffer
+	if err := New(&in, &out, &types.Settings{ProjectPath: dir}).Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []map[string]any
+	r := bufio.NewReader(&out)
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		raw, _ := json.Marshal(msg)
+		var decoded map[string]any
+		json.Unmarshal(raw, &decoded)
+		messages = append(messages, decoded)
+	}
+	return messages
+}
+
+func TestSession(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "demo.go")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(path)
+	doc := map[string]any{"uri": uri}
+
+	// The open buffer has a `@de` line the file on disk doesn't
+	text := strings.Replace(source, "@unknown", "@de", 1)
+	messages := session(t, dir,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"method": "initialized", "params": map[string]any{}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{"textDocument": map[string]any{"uri": uri, "text": text}}},
+		map[string]any{"id": 2, "method": "textDocument/completion", "params": map[string]any{"textDocument": doc, "position": map[string]any{"line": 7, "character": 3}}},
+		map[string]any{"id": 3, "method": "textDocument/hover", "params": map[string]any{"textDocument": doc, "position": map[string]any{"line": 10, "character": 6}}},
+		map[string]any{"id": 4, "method": "textDocument/codeAction", "params": map[string]any{"textDocument": doc, "range": map[string]any{
+			"start": map[string]any{"line": 0, "character": 0}, "end": map[string]any{"line": 20, "character": 0},
+		}}},
+		map[string]any{"id": 5, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+	if len(messages) != 6 {
+		t.Fatalf("got %d messages, want 6: %v", len(messages), messages)
+	}
+
+	encoded, _ := json.Marshal(messages[1]["params"])
+	for _, want := range []string{"DM001", "unknown tag `@de` for header `FUNC`", "DM015", "parameter `b` of `Add` has no description"} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("diagnostics %s are missing %q", encoded, want)
+		}
+	}
+
+	var labels []string
+	for _, item := range messages[2]["result"].([]any) {
+		labels = append(labels, item.(map[string]any)["label"].(string))
+	}
+	if strings.Join(labels, " ") != "@description @desc" {
+		t.Errorf("got completions %v, want [@description @desc]", labels)
+	}
+
+	encoded, _ = json.Marshal(messages[3]["result"])
+	if !strings.Contains(string(encoded), "*Adds two numbers*") {
+		t.Errorf("hover %s doesn't preview the block", encoded)
+	}
+
+	actions := messages[4]["result"].([]any)
+	if len(actions) != 1 {
+		t.Fatalf("got %d code actions, want 1 for `Sub`", len(actions))
+	}
+	encoded, _ = json.Marshal(actions[0])
+	want := `/***\n-- FUNC\n@func Sub\n@desc\n@param a (int):\n@param b (int):\n@ret diff (int):\n*/\n\n`
+	if !strings.Contains(string(encoded), want) {
+		t.Errorf("code action %s doesn't insert %s", encoded, want)
+	}
+}
+
+func TestCompleteCustomTags(t *testing.T) {
+	settings := &types.Settings{CustomTags: []types.CustomTag{{Name: "Owner", Aliases: []string{"OWNED_BY"}}}}
+	text := "package demo\n\n/***\n-- FUNC\n@func Add\n@ow\n*/\nfunc Add() {}\n"
+	d := newDocument("file:///demo.go", text, settings)
+
+	var labels []string
+	for _, item := range d.completion(position{Line: 5, Character: 3}) {
+		labels = append(labels, item.Label)
+	}
+	if strings.Join(labels, " ") != "@Owner @OWNED_BY" {
+		t.Errorf("got completions %v, want [@Owner @OWNED_BY]", labels)
+	}
+}
+
+// Hovers render the item with the same template as `docmate generate`
+func TestHoverPreview(t *testing.T) {
+	settings := &types.Settings{CustomTagsTitle: "Ownership", CustomTags: []types.CustomTag{{Name: "owner", Label: "Owner"}}}
+	text := "package demo\n\n/***\n-- FUNC\n@func Add\n@desc Adds two numbers\n@owner alice\n@param a (int): The first number\n*/\nfunc Add(a int) int { return a }\n"
+	d := newDocument("file:///demo.go", text, settings)
+
+	want := "- ### `Add`\n    - *Adds two numbers*\n    - Ownership:\n        - Owner: alice\n    - Params:\n        - ### `a` (int)\n            - *The first number*\n    - Return values:\n        - (int)\n"
+	// Over the block's text and over the declaration's name
+	for _, pos := range []position{{Line: 5, Character: 16}, {Line: 9, Character: 6}} {
+		h := d.hover(pos)
+		if h == nil || h.Contents.Value != want {
+			t.Errorf("hover at %v: got %+v, want %q", pos, h, want)
+		}
+	}
+}
+
+// Text may follow `/***` on the block's first line
+func TestFirstLine(t *testing.T) {
+	text := "package demo\n\n/*** @f\n-- FUNC\n@func Add\n*/\nfunc Add() {}\n"
+	d := newDocument("file:///demo.go", text, &types.Settings{})
+
+	if items := d.completion(position{Line: 2, Character: 7}); len(items) != 0 {
+		t.Errorf("got completions %v before the header, want none", items)
+	}
+	if h := d.hover(position{Line: 2, Character: 6}); h != nil {
+		t.Errorf("got hover %+v before the header, want none", h)
+	}
+
+	text = "package demo\n\n/*** -- FUNC\n@fu\n*/\nfunc Add() {}\n"
+	d = newDocument("file:///demo.go", text, &types.Settings{})
+	var labels []string
+	for _, item := range d.completion(position{Line: 3, Character: 3}) {
+		labels = append(labels, item.Label)
+	}
+	if strings.Join(labels, " ") != "@func @function" {
+		t.Errorf("got completions %v, want the tags of the header on the first line", labels)
+	}
+}
@@ -0,0 +1,224 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ajtroup1/DocMate/internal/lexer"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Server speaks the Language Server Protocol for DocMate blocks. Open documents are lexed,
+// parsed and linted by the same packages as the CLI, so the editor reports what
+// `docmate lint` reports
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	settings *types.Settings
+	lexer    *lexer.Lexer // Decides which files the CLI reads, the others get no diagnostics
+	docs     map[string]*document
+	shutdown bool
+}
+
+// New serves requests read from in, writing responses to out. The project path of the settings
+// should be absolute
+func New(in io.Reader, out io.Writer, settings *types.Settings) *Server {
+	l := lexer.New(settings.IncludeTests, settings.ProjectPath)
+	l.Include = settings.Include
+	l.Exclude = settings.Exclude
+
+	return &Server{
+		in:       bufio.NewReader(in),
+		out:      out,
+		settings: settings,
+		lexer:    l,
+		docs:     make(map[string]*document),
+	}
+}
+
+// Run serves until the client sends `exit`, or the input or context ends. Exiting without a
+// `shutdown` request first is an error, as the protocol asks
+func (s *Server) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		msg, err := readMessage(s.in)
+		var rpcErr *responseError
+		if errors.As(err, &rpcErr) {
+			s.reply(msg, nil, rpcErr)
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit requested without shutdown")
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handles a request or notification, only failing when the output can't be written
+func (s *Server) handle(msg *message) error {
+	if s.shutdown && msg.ID != nil {
+		return s.reply(msg, nil, &responseError{Code: codeInvalidRequest, Message: "server is shutting down"})
+	}
+
+	switch msg.Method {
+	case "initialize":
+		return s.reply(msg, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   1, // Full documents
+				"completionProvider": map[string]any{"triggerCharacters": []string{"@", "-"}},
+				"hoverProvider":      true,
+				"codeActionProvider": true,
+			},
+			"serverInfo": map[string]any{"name": "docmate"},
+		}, nil)
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(msg, nil, nil)
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		return s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		return s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []diagnostic{}})
+
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		doc, err := s.document(msg, &params)
+		if doc == nil {
+			return s.reply(msg, nil, err)
+		}
+		return s.reply(msg, nonNil(doc.completion(params.Position)), nil)
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		doc, err := s.document(msg, &params)
+		if doc == nil {
+			return s.reply(msg, nil, err)
+		}
+		return s.reply(msg, doc.hover(params.Position), nil)
+	case "textDocument/codeAction":
+		var params codeActionParams
+		doc, err := s.document(msg, &params)
+		if doc == nil {
+			return s.reply(msg, nil, err)
+		}
+		return s.reply(msg, nonNil(doc.codeActions(params.Range)), nil)
+	}
+
+	// Unknown notifications, eg. `initialized` or `$/cancelRequest`, are ignored
+	if msg.ID != nil {
+		return s.reply(msg, nil, &responseError{Code: codeMethodNotFound, Message: "unknown method " + msg.Method})
+	}
+	return nil
+}
+
+// Decodes the params of a request on an open document. A nil document without an error replies
+// with null, eg. for documents that were never opened
+func (s *Server) document(msg *message, params any) (*document, *responseError) {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	var uri string
+	switch params := params.(type) {
+	case *textDocumentPositionParams:
+		uri = params.TextDocument.URI
+	case *codeActionParams:
+		uri = params.TextDocument.URI
+	}
+	return s.docs[uri], nil
+}
+
+// Analyzes the new content of a document and publishes its diagnostics
+func (s *Server) update(uri, text string) error {
+	doc := newDocument(uri, text, s.settings)
+	s.docs[uri] = doc
+
+	diagnostics := []diagnostic{}
+	if reads, _ := s.lexer.Reads(doc.path); reads {
+		sort.SliceStable(doc.diagnostics, func(i, j int) bool {
+			a, b := doc.diagnostics[i].Pos, doc.diagnostics[j].Pos
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Column < b.Column
+		})
+		for _, d := range doc.diagnostics {
+			severity := severityWarning
+			if d.Severity == types.SeverityError {
+				severity = severityError
+			}
+			diagnostics = append(diagnostics, diagnostic{
+				Range:    doc.diagnosticRange(d.Pos),
+				Severity: severity,
+				Code:     d.Code,
+				Source:   "docmate",
+				Message:  d.Message,
+			})
+		}
+	}
+
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+func (s *Server) reply(req *message, result any, rpcErr *responseError) error {
+	// A message without an ID is a notification and gets no reply
+	if req == nil || req.ID == nil {
+		return nil
+	}
+	resp := &message{ID: req.ID, Error: rpcErr}
+	if rpcErr == nil {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = (*json.RawMessage)(&raw)
+	}
+	return writeMessage(s.out, resp)
+}
+
+func (s *Server) notify(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.out, &message{Method: method, Params: raw})
+}
+
+// Empty lists are sent as `[]` rather than `null`
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -2,17 +2,66 @@ package parser
 
 import (
 	"fmt"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"unicode"
 
+	"github.com/ajtroup1/DocMate/internal/resolver"
+	"github.com/ajtroup1/DocMate/internal/schema"
 	"github.com/ajtroup1/DocMate/internal/types"
 )
 
 type Parser struct {
 	comments        []types.CommentBlock
 	Packages        []types.Package
-	Errors          types.Error
+	Diagnostics     []types.Diagnostic
+	Bindings        []resolver.Binding
 	capitalizeItems bool
+	// Files found in the cache skip parsing, so Bindings only cover the files parsed in this
+	// run. Leave it nil when every binding is needed, eg. for linting
+	Cache UnitCache
+	// Content of files that declarations are resolved from instead of the files on disk
+	Sources map[string][]byte
+	// Headers and the tags they accept, see schema.Extend for custom tags. schema.Headers when nil
+	Headers []schema.Header
+}
+
+// Unit is the parsed and resolved result of the comment blocks of a single source file
+type Unit struct {
+	Path        string             `json:"path"`
+	Package     types.Package      `json:"package"` // Holds the file along with any PKG block
+	Diagnostics []types.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// UnitCache stores the units of files whose comment blocks haven't changed
+type UnitCache interface {
+	Unit(path string) (*Unit, bool)
+	SetUnit(path string, unit *Unit)
+}
+
+// A single `@tag value` pair pulled from a comment block
+type tag struct {
+	name  string // As written, lowercased
+	key   string // Canonical name from the schema, empty for tags the header doesn't accept
+	label string // Only set on custom tags
+	many  bool   // Whether the schema lets the tag be given more than once
+	value string // Written the way gofmt would for tags holding a Go type
+	pos   types.Position
+	// Where the value starts, for diagnostics pointing into it
+	valuePos types.Position
+	// Block statements (eg. `@dep { ... }`) hold their own tags
+	isBlock bool
+	block   []tag
+	// Parsed according to the kind of value the schema gives the tag
+	variable types.Variable  // Signatures, eg. `@param`
+	response *types.Response // Nil when the response is invalid
+}
+
+// A line of a comment block along with where it was written
+type sourceLine struct {
+	text string
+	pos  types.Position
 }
 
 func New(comments []types.CommentBlock, capItems bool) *Parser {
@@ -21,21 +70,92 @@ func New(comments []types.CommentBlock, capItems bool) *Parser {
 
 func (p *Parser) ParseComments() {
 	fmt.Print()
+	// Each file is parsed and resolved on its own, then merged into the package tree in the
+	// order its blocks were found
+	for _, comments := range groupByFile(p.comments) {
+		path := comments[0].Filepath
+		unit, ok := p.cachedUnit(path)
+		if !ok {
+			unit = p.parseUnit(comments)
+			if p.Cache != nil {
+				p.Cache.SetUnit(path, unit)
+			}
+		}
+		p.addUnit(unit)
+	}
+
+	// Types, variables and functions are attached to their files while parsing,
+	// so the package-level lists are built once every block has been evaluated
+	p.collectPackageItems()
+}
+
+func (p *Parser) cachedUnit(path string) (*Unit, bool) {
+	if p.Cache == nil {
+		return nil, false
+	}
+	return p.Cache.Unit(path)
+}
+
+// Parses the blocks of a single file, which all belong to the same package
+func (p *Parser) parseUnit(comments []types.CommentBlock) *Unit {
+	file := &Parser{comments: comments, capitalizeItems: p.capitalizeItems, Sources: p.Sources, Headers: p.Headers}
+
 	// First, retrieve all package names to properly assign the root nodes for structured data
-	pkgNames := p.retrievePackages()
+	pkgNames := file.retrievePackages()
 
 	// Initialize all packages before evalutaion so you can assign nodes to a root
 	for _, name := range pkgNames {
-		p.createPackage(name)
+		file.createPackage(name)
 	}
 
-	// fmt.Printf("%v\n", p.Packages)
+	for _, comment := range comments {
+		file.parseIndividualCommentBlock(comment)
+	}
 
-	for _, comment := range p.comments {
-		p.parseIndividualCommentBlock(comment)
+	// Tie each block to the declaration it documents before the package-level lists are built
+	file.resolveDeclarations()
+	p.Bindings = append(p.Bindings, file.Bindings...)
+
+	return &Unit{Path: comments[0].Filepath, Package: file.Packages[0], Diagnostics: file.Diagnostics}
+}
+
+// Merges a file's unit into its package, PKG blocks in later files override earlier ones
+func (p *Parser) addUnit(unit *Unit) {
+	pkg := p.findPackage(unit.Package.Name)
+	if unit.Package.Pos != (types.Position{}) {
+		pkg.Pos = unit.Package.Pos
+	}
+	if unit.Package.Desc != "" {
+		pkg.Desc = unit.Package.Desc
+	}
+	if unit.Package.Usage != "" {
+		pkg.Usage = unit.Package.Usage
+	}
+	if unit.Package.Meta != nil {
+		pkg.Meta = unit.Package.Meta
+	}
+	pkg.Deps = append(pkg.Deps, unit.Package.Deps...)
+	pkg.Files = append(pkg.Files, unit.Package.Files...)
+
+	p.Diagnostics = append(p.Diagnostics, unit.Diagnostics...)
+}
+
+// Groups the comment blocks by file, keeping the order files were first seen in
+func groupByFile(comments []types.CommentBlock) [][]types.CommentBlock {
+	var groups [][]types.CommentBlock
+	index := make(map[string]int)
+
+	for _, comment := range comments {
+		i, ok := index[comment.Filepath]
+		if !ok {
+			i = len(groups)
+			index[comment.Filepath] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], comment)
 	}
 
-	// fmt.Printf("%v\n", p.Packages)
+	return groups
 }
 
 func (p *Parser) retrievePackages() []string {
@@ -49,7 +169,7 @@ func (p *Parser) retrievePackages() []string {
 		if !uniquePkgs[lowerPkgName] {
 			// CapitalizeItems in the settings
 			if p.capitalizeItems {
-				comment.Package = strings.ToUpper(string(comment.Package[0])) + comment.Package[1:]
+				comment.Package = capitalize(comment.Package)
 			}
 
 			// Mark the lowercase package name as seen
@@ -63,27 +183,162 @@ func (p *Parser) retrievePackages() []string {
 }
 
 func (p *Parser) parseIndividualCommentBlock(comment types.CommentBlock) {
-	// pkgName := comment.Package
-	// header := ""
+	if len(comment.Text) == 0 {
+		return
+	}
+
+	lines := sourceLines(comment)
 
 	// Determine the header value
-	if strings.HasPrefix(strings.TrimSpace(comment.Text[0]), "--") {
-		// User is declaring a header value
-		comment.Text[0] = strings.TrimSpace(strings.TrimPrefix(comment.Text[0], "--"))
-		// header = comment.Text[0]
+	if !strings.HasPrefix(lines[0].text, "--") {
+		p.errorf(lines[0].pos, types.CodeMissingHeader, "comment block is missing a header (eg. `-- FUNC`)")
+		return
 	}
-	// fmt.Printf("%s\n", header)
+	header := strings.TrimSpace(strings.TrimPrefix(lines[0].text, "--"))
 
 	// Remove the header line before evaluation
-	comment.Text = comment.Text[1:]
+	tags, _, _ := p.extractTags(lines[1:], 0)
+
+	headers := p.Headers
+	if headers == nil {
+		headers = schema.Headers
+	}
+	spec, ok := schema.Find(headers, header)
+	if !ok {
+		for _, t := range tags {
+			if t.isBlock {
+				p.errorf(t.pos, types.CodeBlockNotAllowed, "tag `@%s` does not accept a block statement", t.name)
+			}
+		}
+		p.errorf(lines[0].pos, types.CodeUnknownHeader, "unknown header `%s`", header)
+		return
+	}
+	for _, missing := range p.resolveTags(tags, spec.Tags, "") {
+		p.missingTag(lines[0].pos, missing, "")
+	}
+
+	switch spec.Name {
+	case schema.HeaderPackage:
+		p.parsePackage(comment, tags)
+	case schema.HeaderFile:
+		p.parseFile(comment, tags)
+	case schema.HeaderType:
+		p.parseType(comment, tags)
+	case schema.HeaderVariable:
+		p.parseVariable(comment, tags)
+	case schema.HeaderFunction:
+		p.parseFunction(comment, tags)
+	}
+}
+
+// Maps every tag to its canonical name and parses its value according to its kind, reporting
+// block statements the tag doesn't accept and tags given more often than the schema allows.
+// Required tags that weren't given a value are returned. within names the enclosing block in
+// messages
+func (p *Parser) resolveTags(tags []tag, specs []schema.Tag, within string) []schema.Tag {
+	seen := make(map[string]bool)
+	given := make(map[string]bool)
+	for i := range tags {
+		t := &tags[i]
+		spec, ok := schema.Lookup(specs, t.name)
+		if t.isBlock && (!ok || spec.Tags == nil) {
+			p.errorf(t.pos, types.CodeBlockNotAllowed, "tag `@%s`%s does not accept a block statement", t.name, within)
+		}
+		if !ok {
+			continue
+		}
+
+		t.key, t.label, t.many = spec.Name, spec.Label, spec.Cardinality == schema.Many
+		if seen[t.key] && !t.many {
+			p.warnf(t.pos, types.CodeDuplicateTag, "tag `@%s` is given more than once%s, the last one is used", t.name, within)
+		}
+		seen[t.key] = true
+		if t.value != "" || t.isBlock {
+			given[t.key] = true
+		}
+		p.parseValue(t, spec.Kind)
+	}
+
+	var missing []schema.Tag
+	for _, spec := range specs {
+		if spec.Cardinality == schema.Required && !given[spec.Name] {
+			missing = append(missing, spec)
+		}
+	}
+	return missing
+}
+
+// Checks the value is written the way its kind requires, keeping the parsed value on the tag
+func (p *Parser) parseValue(t *tag, kind schema.Kind) {
+	switch kind {
+	case schema.KindSignature:
+		t.variable = p.parseSignature(*t)
+	case schema.KindType:
+		if t.value == "" {
+			return
+		}
+		typ, err := checkType(t.value)
+		if err != nil {
+			p.errorf(t.valueAt(err.offset), types.CodeInvalidValue, "invalid `@%s` type: %s", t.name, err.message)
+			return
+		}
+		t.value = typ
+	case schema.KindResponse:
+		res, err := parseResponse(t.value)
+		if err != nil {
+			p.errorf(t.pos, types.CodeInvalidValue, "%v", err)
+			return
+		}
+		t.response = &res
+	}
+}
 
-	// Deconstruct the tags
-	for _, line := range comment.Text {
+// Reports a required tag that wasn't given, eg. a `-- FUNC` block without `@func`
+func (p *Parser) missingTag(pos types.Position, spec schema.Tag, within string) {
+	p.errorf(pos, types.CodeMissingName, "missing required tag `@%s`%s (%s)", spec.Name, within, strings.TrimSuffix(spec.Doc, "."))
+}
+
+// Deconstructs the lines of a comment block into tags. Block statements are extracted
+// recursively, so the remaining lines and whether the block was closed are returned
+func (p *Parser) extractTags(lines []sourceLine, depth int) ([]tag, []sourceLine, bool) {
+	var tags []tag
+
+	for len(lines) > 0 {
 		// Determine the tag
-		line = skipWhitespace(line)
+		line := skipWhitespace(lines[0].text)
+		pos := lines[0].pos
+		lines = lines[1:]
+
+		if line == "}" {
+			if depth == 0 {
+				p.errorf(pos, types.CodeUnexpectedBrace, "unexpected `}` outside of a block statement")
+				continue
+			}
+			return tags, lines, true
+		}
 
-		p.parseLine(line)
+		name, value, ok := p.parseLine(line)
+		if !ok {
+			continue
+		}
+
+		t := tag{name: strings.ToLower(name), value: value, pos: pos, valuePos: pos}
+		// Lines are trimmed, so the value runs to the end of the line
+		t.valuePos.Column += len(line) - len(value)
+		if strings.HasSuffix(value, "{") {
+			var closed bool
+			t.isBlock = true
+			t.value = strings.TrimSpace(strings.TrimSuffix(value, "{"))
+			t.block, lines, closed = p.extractTags(lines, depth+1)
+			if !closed {
+				p.errorf(pos, types.CodeUnclosedBlock, "block statement `@%s` is missing a closing `}`", name)
+			}
+		}
+
+		tags = append(tags, t)
 	}
+
+	return tags, nil, depth == 0
 }
 
 func (p *Parser) createPackage(name string) {
@@ -94,14 +349,272 @@ func (p *Parser) createPackage(name string) {
 	p.Packages = append(p.Packages, pkg)
 }
 
-func (p *Parser) parseLine(line string) {
-	// fmt.Printf("%s\n", line)
-	if strings.HasPrefix(line, "@") {
-		line = line[1:]
-		// Extract the tag name
-		tag, line := extractTagName(line)
-		fmt.Printf("%s\n%s\n\n", tag, line)
+func (p *Parser) parsePackage(comment types.CommentBlock, tags []tag) {
+	pkg := p.findPackage(comment.Package)
+	pkg.Pos = position(comment)
+
+	for _, t := range tags {
+		switch t.key {
+		case schema.TagPackage:
+			// The package is always named by its package clause, the tag is only a confirmation
+			if !strings.EqualFold(t.value, comment.Package) {
+				p.warnf(t.pos, types.CodeNameMismatch, "package name `%s` does not match package clause `%s`", t.value, comment.Package)
+			}
+		case schema.TagDescription:
+			pkg.Desc = t.value
+		case schema.TagUsage:
+			pkg.Usage = t.value
+		case schema.TagDependency:
+			pkg.Deps = append(pkg.Deps, p.parseDependency(t))
+		default:
+			p.otherTag(&pkg.Meta, t, "PKG")
+		}
+	}
+}
+
+func (p *Parser) parseFile(comment types.CommentBlock, tags []tag) {
+	file := p.findFile(comment)
+	file.Pos = position(comment)
+
+	for _, t := range tags {
+		switch t.key {
+		case schema.TagFile:
+			file.Name = t.value
+			if p.capitalizeItems {
+				file.Name = capitalize(file.Name)
+			}
+		case schema.TagDescription:
+			file.Desc = t.value
+		case schema.TagAuthor:
+			file.Auth = t.value
+		case schema.TagVersion:
+			file.Version = t.value
+		case schema.TagDate:
+			file.Date = t.value
+		case schema.TagDependency:
+			file.Deps = append(file.Deps, p.parseDependency(t))
+		default:
+			p.otherTag(&file.Meta, t, "FILE")
+		}
+	}
+}
+
+func (p *Parser) parseType(comment types.CommentBlock, tags []tag) {
+	typ := types.Type{Pos: position(comment)}
+
+	for _, t := range tags {
+		switch t.key {
+		case schema.TagType:
+			typ.Name = t.value
+		case schema.TagDescription:
+			typ.Desc = t.value
+		case schema.TagField:
+			typ.Fields = append(typ.Fields, t.variable)
+		default:
+			p.otherTag(&typ.Meta, t, "TYPE")
+		}
 	}
+
+	// A missing name was reported while resolving the tags
+	if typ.Name == "" {
+		return
+	}
+	typ.Exported = isExported(typ.Name)
+
+	file := p.findFile(comment)
+	file.Types = append(file.Types, typ)
+}
+
+func (p *Parser) parseVariable(comment types.CommentBlock, tags []tag) {
+	variable := types.Variable{Pos: position(comment)}
+
+	for _, t := range tags {
+		switch t.key {
+		case schema.TagVariable:
+			variable.Name = t.value
+		case schema.TagType:
+			variable.Type = t.value
+		case schema.TagDescription:
+			variable.Desc = t.value
+		default:
+			p.otherTag(&variable.Meta, t, "VAR")
+		}
+	}
+
+	if variable.Name == "" {
+		return
+	}
+	variable.Exported = isExported(variable.Name)
+
+	file := p.findFile(comment)
+	file.Vars = append(file.Vars, variable)
+}
+
+func (p *Parser) parseFunction(comment types.CommentBlock, tags []tag) {
+	function := types.Function{Pos: position(comment)}
+
+	for _, t := range tags {
+		switch t.key {
+		case schema.TagFunction:
+			// Methods can be declared with their receiver, eg. `@func (h *Handler) Serve`
+			receiver, name := splitReceiver(t.value)
+			function.Name = name
+			if receiver != "" {
+				function.Receiver = &types.Type{Name: receiver, Exported: isExported(receiver), Pos: t.pos}
+			}
+		case schema.TagDescription:
+			function.Desc = t.value
+		case schema.TagParam:
+			function.Params = append(function.Params, t.variable)
+		case schema.TagReturn:
+			function.Returns = append(function.Returns, types.ReturnValue{Variable: t.variable, IsError: isErrorType(t.variable.Type)})
+		case schema.TagReceiver:
+			function.Receiver = &types.Type{Name: t.value, Exported: isExported(t.value), Pos: t.pos}
+		case schema.TagResponse:
+			if t.response != nil {
+				function.Responses = append(function.Responses, *t.response)
+			}
+		case schema.TagExample:
+			function.Examples = append(function.Examples, types.Example{Code: t.value})
+		default:
+			p.otherTag(&function.Meta, t, "FUNC")
+		}
+	}
+
+	if function.Name == "" {
+		return
+	}
+	function.Exported = isExported(function.Name)
+
+	file := p.findFile(comment)
+	file.Funcs = append(file.Funcs, function)
+}
+
+// Returns the package a comment block belongs to, creating it if it wasn't retrieved beforehand
+func (p *Parser) findPackage(name string) *types.Package {
+	for i := range p.Packages {
+		if strings.EqualFold(p.Packages[i].Name, name) {
+			return &p.Packages[i]
+		}
+	}
+
+	if p.capitalizeItems {
+		name = capitalize(name)
+	}
+	p.createPackage(name)
+	return &p.Packages[len(p.Packages)-1]
+}
+
+// Returns the file a comment block belongs to, creating it under the block's package if needed
+func (p *Parser) findFile(comment types.CommentBlock) *types.File {
+	pkg := p.findPackage(comment.Package)
+
+	for i := range pkg.Files {
+		if pkg.Files[i].Path == comment.Filepath {
+			return &pkg.Files[i]
+		}
+	}
+
+	// Default the name to the file's base name until a FILE block names it
+	name := filepath.Base(comment.Filepath)
+	if p.capitalizeItems {
+		name = capitalize(name)
+	}
+	pkg.Files = append(pkg.Files, types.File{Path: comment.Filepath, Name: name})
+	return &pkg.Files[len(pkg.Files)-1]
+}
+
+func (p *Parser) resolveDeclarations() {
+	r := resolver.New()
+	r.Sources = p.Sources
+
+	for i := range p.Packages {
+		for j := range p.Packages[i].Files {
+			r.ResolveFile(&p.Packages[i].Files[j])
+		}
+	}
+
+	p.Bindings = r.Bindings
+	p.Diagnostics = append(p.Diagnostics, r.Diagnostics...)
+}
+
+func (p *Parser) collectPackageItems() {
+	for i := range p.Packages {
+		pkg := &p.Packages[i]
+		pkg.Types, pkg.Vars, pkg.Funcs = nil, nil, nil
+
+		for _, file := range pkg.Files {
+			pkg.Types = append(pkg.Types, file.Types...)
+			pkg.Vars = append(pkg.Vars, file.Vars...)
+			pkg.Funcs = append(pkg.Funcs, file.Funcs...)
+		}
+	}
+}
+
+func position(comment types.CommentBlock) types.Position {
+	return types.Position{Filepath: comment.Filepath, Line: comment.Line, Column: comment.Column}
+}
+
+// Pairs each line of a comment block with its position, falling back to the block's position
+func sourceLines(comment types.CommentBlock) []sourceLine {
+	lines := make([]sourceLine, len(comment.Text))
+	for i, text := range comment.Text {
+		lines[i] = sourceLine{text: strings.TrimSpace(text), pos: position(comment)}
+		if i < len(comment.TextPos) {
+			lines[i].pos = comment.TextPos[i]
+		}
+	}
+	return lines
+}
+
+// Keeps a custom tag as metadata, reporting any other tag as unknown. A custom tag that can't
+// repeat replaces its earlier value
+func (p *Parser) otherTag(meta *[]types.Metadata, t tag, header string) {
+	if t.label == "" {
+		p.unknownTag(t, header)
+		return
+	}
+
+	entry := types.Metadata{Key: t.key, Label: t.label, Value: t.value}
+	for i := range *meta {
+		if (*meta)[i].Key == t.key && !t.many {
+			(*meta)[i] = entry
+			return
+		}
+	}
+	*meta = append(*meta, entry)
+}
+
+func (p *Parser) unknownTag(t tag, header string) {
+	p.warnf(t.pos, types.CodeUnknownTag, "unknown tag `@%s` for header `%s`", t.name, header)
+}
+
+func (p *Parser) errorf(pos types.Position, code, format string, args ...any) {
+	p.addDiagnostic(pos, types.SeverityError, code, fmt.Sprintf(format, args...))
+}
+
+func (p *Parser) warnf(pos types.Position, code, format string, args ...any) {
+	p.addDiagnostic(pos, types.SeverityWarning, code, fmt.Sprintf(format, args...))
+}
+
+func (p *Parser) addDiagnostic(pos types.Position, severity types.Severity, code, message string) {
+	p.Diagnostics = append(p.Diagnostics, types.Diagnostic{Pos: pos, Severity: severity, Code: code, Message: message})
+}
+
+// Splits a tag line into its name and value, ok is false if the line isn't a tag
+func (p *Parser) parseLine(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, "@") {
+		return "", "", false
+	}
+	line = line[1:]
+
+	// Extract the tag name
+	tag, line := extractTagName(line)
+	if tag == "" {
+		return "", "", false
+	}
+
+	return tag, strings.TrimSpace(line), true
 }
 
 func extractTagName(line string) (string, string) {
@@ -118,6 +631,99 @@ func extractTagName(line string) (string, string) {
 	return tagName.String(), strings.TrimLeftFunc(line[i:], unicode.IsSpace)
 }
 
+// Parses a response value such as `404 Not Found - If the user does not exist.`
+func parseResponse(value string) (types.Response, error) {
+	codeStr, desc, _ := strings.Cut(value, " ")
+	code, err := strconv.Atoi(codeStr)
+	if err != nil {
+		return types.Response{}, fmt.Errorf("invalid response code `%s`", codeStr)
+	}
+
+	return types.Response{Code: code, Desc: strings.TrimSpace(desc)}, nil
+}
+
+// Parses a dependency, either as a block statement or a single line
+func (p *Parser) parseDependency(t tag) types.Dependancy {
+	if !t.isBlock {
+		return parseInlineDependency(t.value)
+	}
+
+	// Anything written before the brace names the dependency, eg. `@dep MyDep {`
+	dep := types.Dependancy{Name: t.value}
+	within := " in `@" + t.name + "`"
+	for _, missing := range p.resolveTags(t.block, schema.DependencyTags, within) {
+		if missing.Name == schema.TagName && dep.Name != "" {
+			continue
+		}
+		p.missingTag(t.pos, missing, within)
+	}
+	for _, field := range t.block {
+		if field.isBlock {
+			continue
+		}
+
+		switch field.key {
+		case schema.TagName:
+			dep.Name = field.value
+		case schema.TagDescription:
+			dep.Desc = field.value
+		case schema.TagLink:
+			dep.Link = field.value
+		case schema.TagImport:
+			dep.ImportPath = field.value
+		default:
+			p.warnf(field.pos, types.CodeUnknownTag, "unknown tag `@%s` in `@%s` block", field.name, t.name)
+		}
+	}
+
+	return dep
+}
+
+// Parses a single-line dependency such as `(Repository) Depends on the repository package`
+func parseInlineDependency(value string) types.Dependancy {
+	// Dependencies are named rather than typed, so anything may be written between the parentheses
+	sig, _ := splitSignature(value)
+	if !sig.hasType {
+		return types.Dependancy{Name: sig.name, Desc: sig.desc}
+	}
+
+	return types.Dependancy{Name: strings.TrimSpace(sig.typ), Desc: sig.desc}
+}
+
+// Splits `(h *Handler) Serve` into its receiver type (`Handler`) and name (`Serve`)
+func splitReceiver(value string) (string, string) {
+	if !strings.HasPrefix(value, "(") {
+		return "", value
+	}
+
+	end := strings.Index(value, ")")
+	if end == -1 {
+		return "", value
+	}
+
+	fields := strings.Fields(value[1:end])
+	receiver := ""
+	if len(fields) > 0 {
+		receiver = strings.TrimPrefix(fields[len(fields)-1], "*")
+	}
+
+	return receiver, strings.TrimSpace(value[end+1:])
+}
+
+func isExported(name string) bool {
+	for _, ch := range name {
+		return unicode.IsUpper(ch)
+	}
+	return false
+}
+
+func capitalize(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(string(name[0])) + name[1:]
+}
+
 // Skips any whitespace up until it reaches an actual char value
 func skipWhitespace(line string) string {
 	for i, ch := range line {
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Parses a single comment block, without resolving it against the Go source, as if each line
+// was written on its own line of the file starting at line 1
+func parseBlock(lines ...string) *Parser {
+	comment := types.CommentBlock{Filepath: "p.go", Package: "p", Text: lines, Line: 1, Column: 1}
+	for i := range lines {
+		comment.TextPos = append(comment.TextPos, types.Position{Filepath: "p.go", Line: i + 1, Column: 1})
+	}
+
+	p := New(nil, false)
+	p.parseIndividualCommentBlock(comment)
+	return p
+}
+
+// Codes of the diagnostics along with the line they were reported on
+func codes(diagnostics []types.Diagnostic) []string {
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.Code+":"+strings.TrimPrefix(d.Pos.String(), "p.go:"))
+	}
+	return got
+}
+
+func TestDependencyBlocks(t *testing.T) {
+	p := parseBlock(
+		"-- PKG",
+		"@pkg p",
+		"@dep {",
+		"@name mux",
+		"@desc Router",
+		"@link https://github.com/gorilla/mux",
+		"@import github.com/gorilla/mux",
+		"}",
+		"@dep Named {",
+		"@desc Named before the brace",
+		"}",
+		"@dep (sqlx) SQL helpers",
+	)
+	if len(p.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics %v", p.Diagnostics)
+	}
+
+	want := []types.Dependancy{
+		{Name: "mux", Desc: "Router", Link: "https://github.com/gorilla/mux", ImportPath: "github.com/gorilla/mux"},
+		{Name: "Named", Desc: "Named before the brace"},
+		{Name: "sqlx", Desc: "SQL helpers"},
+	}
+	if got := p.Packages[0].Deps; !reflect.DeepEqual(got, want) {
+		t.Errorf("got dependencies %+v, want %+v", got, want)
+	}
+}
+
+func TestBlockDiagnostics(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "missing closing brace",
+			lines: []string{"-- FILE", "@dep {", "@name mux"},
+			want:  []string{"DM005:2:1"},
+		},
+		{
+			name:  "stray closing brace",
+			lines: []string{"-- FILE", "@desc File", "}"},
+			want:  []string{"DM006:3:1"},
+		},
+		{
+			name:  "block on a tag without one",
+			lines: []string{"-- FUNC", "@func Add", "@desc {", "}"},
+			want:  []string{"DM007:3:1"},
+		},
+		{
+			name:  "block nested in a dependency",
+			lines: []string{"-- PKG", "@dep {", "@name mux", "@link {", "}", "}"},
+			want:  []string{"DM007:4:1"},
+		},
+		{
+			name:  "dependency block without a name",
+			lines: []string{"-- PKG", "@dep {", "@desc Router", "}"},
+			want:  []string{"DM004:2:1"},
+		},
+		{
+			name:  "dependency named before the brace",
+			lines: []string{"-- PKG", "@dep mux {", "@desc Router", "}"},
+		},
+		{
+			name:  "function without a name",
+			lines: []string{"-- FUNC", "@desc Adds"},
+			want:  []string{"DM004:1:1"},
+		},
+		{
+			name:  "type with an empty name",
+			lines: []string{"-- TYPE", "@type", "@desc Pair"},
+			want:  []string{"DM004:1:1"},
+		},
+		{
+			name:  "variable type that isn't a type",
+			lines: []string{"-- VAR", "@var limit", "@type f()"},
+			want:  []string{"DM008:3:7"},
+		},
+		{
+			name:  "response without a code",
+			lines: []string{"-- FUNC", "@func Serve", "@res OK"},
+			want:  []string{"DM008:3:1"},
+		},
+	}
+
+	for _, test := range tests {
+		p := parseBlock(test.lines...)
+		if got := codes(p.Diagnostics); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// Values are parsed according to the kind of value the schema gives the tag
+func TestTagKinds(t *testing.T) {
+	p := parseBlock("-- VAR", "@var counts", "@type map[string] []int")
+	if typ := p.Packages[0].Files[0].Vars[0].Type; typ != "map[string][]int" {
+		t.Errorf("got type %q, want it written the way gofmt would", typ)
+	}
+
+	p = parseBlock("-- FUNC", "@desc Adds")
+	if len(p.Diagnostics) != 1 || p.Diagnostics[0].Message != "missing required tag `@func` (Name of the function, methods may include their receiver, eg. `(h *Handler) Serve`)" {
+		t.Errorf("got %v, want the missing `@func` reported", p.Diagnostics)
+	}
+
+	p = parseBlock("-- FUNC", "@func Serve", "@res 404 Not found", "@res OK")
+	if responses := p.Packages[0].Files[0].Funcs[0].Responses; len(responses) != 1 || responses[0].Code != 404 {
+		t.Errorf("got responses %+v, want only the valid one", responses)
+	}
+}
@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"github.com/ajtroup1/DocMate/internal/resolver"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// The parts of a `@param`, `@return` or `@field` value. Every part is optional:
+//
+//	signature = [ name ] [ "(" type ")" ] [ ":" ] [ description ]
+//
+// eg. `dbConn (*sql.DB): Database connection`, `(error) When it fails` or `id: The user's ID`.
+// The type is any Go type, including generic, function, channel and map types
+type signature struct {
+	name, typ, desc string
+	hasType         bool
+	typeOffset      int // Byte offset of the type's `(` in the value
+}
+
+// A grammar violation at a byte offset of the value
+type signatureError struct {
+	offset  int
+	message string
+}
+
+func (e *signatureError) Error() string {
+	return e.message
+}
+
+var closing = map[byte]byte{'(': ')', '[': ']', '{': '}'}
+
+// Splits a value into its parts, only checking that the brackets of the type balance. Parts found
+// before an error are kept
+func splitSignature(value string) (signature, *signatureError) {
+	var sig signature
+
+	// The name runs up to a space, the type or the colon
+	end := strings.IndexFunc(value, func(r rune) bool { return unicode.IsSpace(r) || r == '(' || r == ':' })
+	if end == -1 {
+		end = len(value)
+	}
+	sig.name = value[:end]
+
+	rest := end + len(value[end:]) - len(strings.TrimLeftFunc(value[end:], unicode.IsSpace))
+	if rest < len(value) && value[rest] == '(' {
+		sig.hasType, sig.typeOffset = true, rest
+		close, err := matchBracket(value, rest)
+		if err != nil {
+			return sig, err
+		}
+		sig.typ = value[rest+1 : close]
+		rest = close + 1
+	}
+
+	sig.desc = strings.TrimSpace(value[rest:])
+	// The colon is optional, eg. `(*UserHandler) Initialized handler`
+	sig.desc = strings.TrimSpace(strings.TrimPrefix(sig.desc, ":"))
+	return sig, nil
+}
+
+// Returns the offset of the bracket closing the one at open
+func matchBracket(value string, open int) (int, *signatureError) {
+	var stack []int
+	for i := open; i < len(value); i++ {
+		switch ch := value[i]; ch {
+		case '"', '`':
+			// Struct tags may hold brackets, eg. `struct{ A int "json:\"a,omitempty\"" }`
+			i = skipString(value, i)
+		case '(', '[', '{':
+			stack = append(stack, i)
+		case ')', ']', '}':
+			top := stack[len(stack)-1]
+			if want := closing[value[top]]; ch != want {
+				return 0, &signatureError{i, fmt.Sprintf("unexpected `%c`, expected `%c`", ch, want)}
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i, nil
+			}
+		}
+	}
+
+	top := stack[len(stack)-1]
+	return 0, &signatureError{top, fmt.Sprintf("`%c` is never closed", value[top])}
+}
+
+// Returns the offset of the quote closing the string at start, or the end of the value
+func skipString(value string, start int) int {
+	for i := start + 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if value[start] == '"' {
+				i++
+			}
+		case value[start]:
+			return i
+		}
+	}
+	return len(value)
+}
+
+// Parses a `@param`, `@return` or `@field` value, reporting where the grammar is violated. The
+// type is kept the way gofmt writes it, eg. `map[string]int` for `map[string] int`
+func (p *Parser) parseSignature(t tag) types.Variable {
+	variable := types.Variable{Pos: t.pos}
+
+	sig, err := splitSignature(t.value)
+	variable.Name, variable.Desc = sig.name, sig.desc
+	if err != nil {
+		p.errorf(t.valueAt(err.offset), types.CodeInvalidValue, "invalid `@%s` type: %s", t.name, err.message)
+		variable.Exported = isExported(variable.Name)
+		return variable
+	}
+
+	if sig.name != "" && !token.IsIdentifier(sig.name) {
+		p.errorf(t.valueAt(0), types.CodeInvalidValue, "`%s` is not a valid `@%s` name, expected `name (Type): Description`", sig.name, t.name)
+	}
+	if sig.hasType {
+		typ, err := checkType(sig.typ)
+		if err != nil {
+			// The type starts after the `(`
+			p.errorf(t.valueAt(sig.typeOffset+1+err.offset), types.CodeInvalidValue, "invalid `@%s` type: %s", t.name, err.message)
+		} else {
+			variable.Type = typ
+		}
+	}
+
+	variable.Exported = isExported(variable.Name)
+	return variable
+}
+
+// Parses a type written between the parentheses, returning it formatted
+func checkType(typ string) (string, *signatureError) {
+	start := len(typ) - len(strings.TrimLeftFunc(typ, unicode.IsSpace))
+	text := strings.TrimSpace(typ)
+	if text == "" {
+		return "", &signatureError{0, "the type is empty, eg. `(int)`"}
+	}
+
+	// Variadic params, eg. `(...string)`
+	variadic := strings.HasPrefix(text, "...")
+	if variadic {
+		text = strings.TrimPrefix(text, "...")
+		start += len("...")
+	}
+
+	fset := token.NewFileSet()
+	expr, err := goparser.ParseExprFrom(fset, "", text, 0)
+	if err != nil {
+		if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+			return "", &signatureError{start + list[0].Pos.Offset, fmt.Sprintf("`%s` %s", text, list[0].Msg)}
+		}
+		return "", &signatureError{start, fmt.Sprintf("`%s` %v", text, err)}
+	}
+	if bad := nonType(expr); bad != nil {
+		offset := fset.Position(bad.Pos()).Offset
+		return "", &signatureError{start + offset, fmt.Sprintf("`%s` is not a type", text[offset:fset.Position(bad.End()).Offset])}
+	}
+
+	formatted := resolver.ExprString(fset, expr)
+	if variadic {
+		formatted = "..." + formatted
+	}
+	return formatted, nil
+}
+
+// Returns the first part of the expression that can't be a type, eg. `f()` or `a + b`
+func nonType(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident, *ast.FuncType, *ast.InterfaceType, *ast.StructType:
+		return nil
+	case *ast.SelectorExpr:
+		// Qualified identifiers only, eg. `sql.DB`
+		if _, ok := e.X.(*ast.Ident); ok {
+			return nil
+		}
+		return e
+	case *ast.StarExpr:
+		return nonType(e.X)
+	case *ast.ParenExpr:
+		return nonType(e.X)
+	case *ast.ArrayType:
+		return nonType(e.Elt)
+	case *ast.MapType:
+		if bad := nonType(e.Key); bad != nil {
+			return bad
+		}
+		return nonType(e.Value)
+	case *ast.ChanType:
+		return nonType(e.Value)
+	case *ast.IndexExpr:
+		if bad := nonType(e.X); bad != nil {
+			return bad
+		}
+		return nonType(e.Index)
+	case *ast.IndexListExpr:
+		if bad := nonType(e.X); bad != nil {
+			return bad
+		}
+		for _, index := range e.Indices {
+			if bad := nonType(index); bad != nil {
+				return bad
+			}
+		}
+		return nil
+	}
+	return expr
+}
+
+// Results of type `error` are flagged so they can be told apart
+func isErrorType(typ string) bool {
+	return typ == "error"
+}
+
+// Position of a byte offset into the tag's value
+func (t tag) valueAt(offset int) types.Position {
+	pos := t.valuePos
+	pos.Column += offset
+	return pos
+}
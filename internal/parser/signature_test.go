@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+func TestParseSignature(t *testing.T) {
+	tests := []struct {
+		value      string
+		name, typ  string
+		desc       string
+		diagnostic string // Empty when the value is valid
+		column     int    // Column of the diagnostic, the value starts at column 10
+	}{
+		{value: "dbConn (*sql.DB): Database connection", name: "dbConn", typ: "*sql.DB", desc: "Database connection"},
+		{value: "(*UserHandler) Initialized handler", typ: "*UserHandler", desc: "Initialized handler"},
+		{value: "id: The user's ID", name: "id", desc: "The user's ID"},
+		{value: "id The user's ID", name: "id", desc: "The user's ID"},
+		{value: "m (map[string] []int): Counts", name: "m", typ: "map[string][]int", desc: "Counts"},
+		{value: "fn (func(int) (string, error)) Callback", name: "fn", typ: "func(int) (string, error)", desc: "Callback"},
+		{value: "ch (<-chan List[T]): Updates (buffered)", name: "ch", typ: "<-chan List[T]", desc: "Updates (buffered)"},
+		{value: "p (Pair[K, V]):", name: "p", typ: "Pair[K, V]"},
+		{value: "rest (...string): Remaining", name: "rest", typ: "...string", desc: "Remaining"},
+		// Types are formatted the way gofmt writes them
+		{value: `s (struct{ A int "json:\")\"" }): Tagged`, name: "s", typ: "struct {\n\tA int \"json:\\\")\\\"\"\n}", desc: "Tagged"},
+		{value: "m (map[string): Counts", column: 23, diagnostic: "unexpected `)`, expected `]`"},
+		{value: "ch (chan int: Updates", column: 13, diagnostic: "`(` is never closed"},
+		{value: "x (): Nothing", column: 13, diagnostic: "the type is empty"},
+		{value: "x (f()): Call", column: 13, diagnostic: "`f()` is not a type"},
+		{value: "x (map[string]): Map", column: 24, diagnostic: "expected type"},
+		{value: "1st (int): First", column: 10, diagnostic: "`1st` is not a valid `@param` name"},
+	}
+
+	for _, test := range tests {
+		p := &Parser{}
+		pos := types.Position{Filepath: "p.go", Line: 3, Column: 1}
+		valuePos := types.Position{Filepath: "p.go", Line: 3, Column: 10}
+		variable := p.parseSignature(tag{name: "param", value: test.value, pos: pos, valuePos: valuePos})
+
+		if test.diagnostic != "" {
+			if len(p.Diagnostics) != 1 || !strings.Contains(p.Diagnostics[0].Message, test.diagnostic) || p.Diagnostics[0].Pos.Column != test.column {
+				t.Errorf("%q: got diagnostics %v, want %q at column %d", test.value, p.Diagnostics, test.diagnostic, test.column)
+			}
+			continue
+		}
+		if len(p.Diagnostics) > 0 {
+			t.Errorf("%q: unexpected diagnostics %v", test.value, p.Diagnostics)
+		}
+		if variable.Name != test.name || variable.Type != test.typ || variable.Desc != test.desc {
+			t.Errorf("%q: got name %q type %q desc %q, want %q %q %q", test.value, variable.Name, variable.Type, variable.Desc, test.name, test.typ, test.desc)
+		}
+	}
+}
+
+func TestReturnIsError(t *testing.T) {
+	p := parseBlock("-- FUNC", "@func Load", "@ret (  error ): When it fails", "@ret (*Config) Loaded settings")
+
+	returns := p.Packages[0].Files[0].Funcs[0].Returns
+	if len(returns) != 2 || !returns[0].IsError || returns[1].IsError {
+		t.Errorf("got returns %+v, want only the first to be an error", returns)
+	}
+}
@@ -0,0 +1,266 @@
+package resolver
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"sort"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Kinds of declarations a comment block can document, named after their headers
+const (
+	KindFunc = "FUNC"
+	KindType = "TYPE"
+	KindVar  = "VAR"
+)
+
+// Resolver ties comment blocks to the Go declarations they document
+type Resolver struct {
+	fset        *token.FileSet
+	Bindings    []Binding
+	Diagnostics []types.Diagnostic
+	Sources     map[string][]byte // Content read instead of the file on disk, eg. unsaved editor buffers
+}
+
+// Binding links a comment block to its declaration
+type Binding struct {
+	Kind string
+	Name string
+	Pos  types.Position // Position of the comment block
+	Decl ast.Node       // *ast.FuncDecl, *ast.TypeSpec, *ast.ValueSpec or *ast.Ident for local variables
+	Fset *token.FileSet
+	Doc  Documented
+}
+
+// Documented holds what the block wrote before the declaration filled it in, so stale
+// documentation can still be detected
+type Documented struct {
+	Params   []types.Variable
+	Returns  []types.ReturnValue
+	Fields   []types.Variable
+	Receiver *types.Type
+	Type     string // Type given to a variable with `@type`
+}
+
+// A declaration that can be documented by a comment block
+type declaration struct {
+	kind  string
+	name  string
+	recv  string // Receiver type name for methods
+	line  int
+	node  ast.Node
+	local bool // Declared inside a function body
+}
+
+func New() *Resolver {
+	return &Resolver{fset: token.NewFileSet()}
+}
+
+// ResolveFile loads the file's source and binds its documented types, variables and functions
+func (r *Resolver) ResolveFile(file *types.File) {
+	if len(file.Types) == 0 && len(file.Vars) == 0 && len(file.Funcs) == 0 {
+		return
+	}
+
+	var src any
+	if content, ok := r.Sources[file.Path]; ok {
+		src = content
+	}
+	f, err := parser.ParseFile(r.fset, file.Path, src, parser.SkipObjectResolution)
+	if err != nil {
+		pos := types.Position{Filepath: file.Path}
+		if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+			pos.Line, pos.Column = list[0].Pos.Line, list[0].Pos.Column
+		}
+		r.addDiagnostic(pos, types.SeverityWarning, types.CodeSourceUnavailable, "could not load declarations: "+err.Error())
+		return
+	}
+	decls := r.collectDeclarations(f)
+
+	for i := range file.Types {
+		typ := &file.Types[i]
+		decl := r.bind(KindType, typ.Name, "", typ.Pos, decls, f, Documented{Fields: typ.Fields})
+		if decl != nil {
+			typ.Exported = ast.IsExported(decl.name)
+			r.fillFields(typ, decl.node.(*ast.TypeSpec))
+		}
+	}
+
+	for i := range file.Vars {
+		variable := &file.Vars[i]
+		decl := r.bind(KindVar, variable.Name, "", variable.Pos, decls, f, Documented{Type: variable.Type})
+		if decl != nil {
+			variable.Exported = ast.IsExported(decl.name) && !decl.local
+			if spec, ok := decl.node.(*ast.ValueSpec); ok {
+				r.fillVariableType(variable, spec)
+			}
+		}
+	}
+
+	for i := range file.Funcs {
+		function := &file.Funcs[i]
+		recv := ""
+		if function.Receiver != nil {
+			recv = function.Receiver.Name
+		}
+		doc := Documented{Params: function.Params, Returns: function.Returns, Receiver: function.Receiver}
+		decl := r.bind(KindFunc, function.Name, recv, function.Pos, decls, f, doc)
+		if decl != nil {
+			function.Exported = ast.IsExported(decl.name)
+			r.fillSignature(function, decl.node.(*ast.FuncDecl))
+		}
+	}
+}
+
+// Finds the declaration a block documents, recording the binding or reporting the missing name
+func (r *Resolver) bind(kind, name, recv string, pos types.Position, decls []declaration, f *ast.File, doc Documented) *declaration {
+	decl := findDeclaration(kind, name, recv, pos.Line, decls)
+	if decl == nil && recv != "" {
+		// A documented receiver that doesn't match is reported as stale documentation instead
+		decl = findDeclaration(kind, name, "", pos.Line, decls)
+	}
+	if decl == nil && kind == KindVar {
+		decl = r.findLocalVariable(name, pos.Line, f)
+	}
+
+	if decl == nil {
+		r.addDiagnostic(pos, types.SeverityError, types.CodeUnknownDeclaration, "no "+describeKind(kind)+" named `"+name+"` is declared in this file")
+		return nil
+	}
+
+	r.Bindings = append(r.Bindings, Binding{Kind: kind, Name: name, Pos: pos, Decl: decl.node, Fset: r.fset, Doc: doc})
+	return decl
+}
+
+// Prefers the first matching declaration following the block, then any matching declaration in the file
+func findDeclaration(kind, name, recv string, line int, decls []declaration) *declaration {
+	var fallback *declaration
+
+	for i := range decls {
+		decl := &decls[i]
+		if decl.kind != kind || decl.name != name || (recv != "" && decl.recv != recv) {
+			continue
+		}
+		if decl.line >= line {
+			return decl
+		}
+		if fallback == nil {
+			fallback = decl
+		}
+	}
+
+	return fallback
+}
+
+func (r *Resolver) collectDeclarations(f *ast.File) []declaration {
+	var decls []declaration
+
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			decls = append(decls, declaration{kind: KindFunc, name: d.Name.Name, recv: ReceiverName(d), line: r.line(d), node: d})
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					decls = append(decls, declaration{kind: KindType, name: spec.Name.Name, line: r.line(spec), node: spec})
+				case *ast.ValueSpec:
+					// Constants are documented with VAR blocks as well
+					for _, ident := range spec.Names {
+						decls = append(decls, declaration{kind: KindVar, name: ident.Name, line: r.line(spec), node: spec})
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(decls, func(i, j int) bool { return decls[i].line < decls[j].line })
+	return decls
+}
+
+// Looks for a variable declared inside the function body that contains the block
+func (r *Resolver) findLocalVariable(name string, line int, f *ast.File) *declaration {
+	for _, d := range f.Decls {
+		fn, ok := d.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || r.line(fn.Body) > line || r.fset.Position(fn.Body.End()).Line < line {
+			continue
+		}
+
+		var found *ast.Ident
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if n.Tok == token.DEFINE {
+					for _, lhs := range n.Lhs {
+						if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+							found = ident
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				for _, ident := range n.Names {
+					if ident.Name == name {
+						found = ident
+					}
+				}
+			}
+			return true
+		})
+
+		if found != nil {
+			return &declaration{kind: KindVar, name: name, line: r.line(found), node: found, local: true}
+		}
+	}
+
+	return nil
+}
+
+func (r *Resolver) line(node ast.Node) int {
+	return r.fset.Position(node.Pos()).Line
+}
+
+func (r *Resolver) addDiagnostic(pos types.Position, severity types.Severity, code, message string) {
+	r.Diagnostics = append(r.Diagnostics, types.Diagnostic{Pos: pos, Severity: severity, Code: code, Message: message})
+}
+
+// ReceiverName returns the receiver's type name without pointers or type parameters, eg. `Handler` for `(h *Handler[T])`
+func ReceiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := fn.Recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func describeKind(kind string) string {
+	switch kind {
+	case KindFunc:
+		return "function"
+	case KindType:
+		return "type"
+	default:
+		return "variable or constant"
+	}
+}
@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Go only rejects the duplicate `Helper` when type checking, which the resolver never does
+const bindSource = `package demo
+
+func Helper() int { return 1 }
+
+type Conn struct {
+	id int
+}
+
+func (c *Conn) Close() error { return nil }
+
+func Close() {}
+
+func Helper() int { return 2 }
+`
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		name     string
+		function types.Function // Pos.Line is the line the block starts on
+		wantLine int            // Line of the bound declaration, 0 when nothing matches
+	}{
+		{name: "first declaration after the block", function: types.Function{Name: "Helper", Pos: pos(2)}, wantLine: 3},
+		{name: "skips earlier declarations", function: types.Function{Name: "Helper", Pos: pos(12)}, wantLine: 13},
+		{name: "falls back to the first declaration", function: types.Function{Name: "Helper", Pos: pos(20)}, wantLine: 3},
+		{name: "method by receiver", function: types.Function{Name: "Close", Receiver: &types.Type{Name: "Conn"}, Pos: pos(1)}, wantLine: 9},
+		{name: "method falls back before the block", function: types.Function{Name: "Close", Receiver: &types.Type{Name: "Conn"}, Pos: pos(10)}, wantLine: 9},
+		{name: "function after the method", function: types.Function{Name: "Close", Pos: pos(10)}, wantLine: 11},
+		{name: "unknown receiver falls back to the name", function: types.Function{Name: "Close", Receiver: &types.Type{Name: "Pool"}, Pos: pos(10)}, wantLine: 11},
+		{name: "no declaration", function: types.Function{Name: "Open", Pos: pos(1)}},
+	}
+
+	for _, test := range tests {
+		r := New()
+		r.Sources = map[string][]byte{"demo.go": []byte(bindSource)}
+		file := types.File{Path: "demo.go", Funcs: []types.Function{test.function}}
+		r.ResolveFile(&file)
+
+		if test.wantLine == 0 {
+			if len(r.Bindings) != 0 || len(r.Diagnostics) != 1 || r.Diagnostics[0].Code != types.CodeUnknownDeclaration {
+				t.Errorf("%s: got bindings %v and diagnostics %v, want DM012", test.name, r.Bindings, r.Diagnostics)
+			}
+			continue
+		}
+		if len(r.Bindings) != 1 || len(r.Diagnostics) != 0 {
+			t.Fatalf("%s: got bindings %v and diagnostics %v, want one binding", test.name, r.Bindings, r.Diagnostics)
+		}
+		if line := r.Bindings[0].Fset.Position(r.Bindings[0].Decl.Pos()).Line; line != test.wantLine {
+			t.Errorf("%s: bound to line %d, want %d", test.name, line, test.wantLine)
+		}
+	}
+}
+
+func TestBindTypesAndVariables(t *testing.T) {
+	r := New()
+	r.Sources = map[string][]byte{"demo.go": []byte(bindSource + "\nconst Max = 3\n\nfunc run() {\n\tcount := 0\n\t_ = count\n}\n")}
+	file := types.File{
+		Path:  "demo.go",
+		Types: []types.Type{{Name: "Conn", Pos: pos(4)}, {Name: "Pool", Pos: pos(4)}},
+		Vars:  []types.Variable{{Name: "Max", Pos: pos(14)}, {Name: "count", Pos: pos(18)}},
+	}
+	r.ResolveFile(&file)
+
+	if len(r.Bindings) != 3 {
+		t.Fatalf("got %d bindings, want Conn, Max and count: %v", len(r.Bindings), r.Bindings)
+	}
+	if len(r.Diagnostics) != 1 || r.Diagnostics[0].Code != types.CodeUnknownDeclaration || r.Diagnostics[0].Message != "no type named `Pool` is declared in this file" {
+		t.Errorf("got diagnostics %v, want DM012 for `Pool`", r.Diagnostics)
+	}
+	if file.Vars[1].Exported || !file.Vars[0].Exported {
+		t.Errorf("got variables %+v, want only `Max` exported", file.Vars)
+	}
+}
+
+func pos(line int) types.Position {
+	return types.Position{Filepath: "demo.go", Line: line, Column: 1}
+}
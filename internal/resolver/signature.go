@@ -0,0 +1,145 @@
+package resolver
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Replaces the documented receiver, params and returns with the real signature, keeping the
+// descriptions written in the block
+func (r *Resolver) fillSignature(function *types.Function, fn *ast.FuncDecl) {
+	if recv := ReceiverName(fn); recv != "" {
+		function.Receiver = &types.Type{Name: recv, Exported: ast.IsExported(recv)}
+	} else {
+		function.Receiver = nil
+	}
+
+	documented := function.Params
+	function.Params = nil
+	for _, param := range r.fieldVariables(fn.Type.Params, false) {
+		if doc := findVariable(documented, param.Name); doc != nil {
+			param.Desc = doc.Desc
+		}
+		function.Params = append(function.Params, param)
+	}
+
+	documentedReturns := function.Returns
+	function.Returns = nil
+	for i, result := range r.fieldVariables(fn.Type.Results, false) {
+		ret := types.ReturnValue{Variable: result, IsError: result.Type == "error"}
+		if doc := findReturn(documentedReturns, result.Name, i); doc != nil {
+			ret.Desc = doc.Desc
+		}
+		function.Returns = append(function.Returns, ret)
+	}
+}
+
+// Replaces the documented fields with the struct's fields, keeping the descriptions written in the block
+func (r *Resolver) fillFields(typ *types.Type, spec *ast.TypeSpec) {
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+
+	documented := typ.Fields
+	typ.Fields = nil
+	for _, field := range r.fieldVariables(st.Fields, true) {
+		if doc := findVariable(documented, field.Name); doc != nil {
+			field.Desc = doc.Desc
+		}
+		typ.Fields = append(typ.Fields, field)
+	}
+}
+
+// Uses the declared type of the variable, inferred types keep whatever the block gave
+func (r *Resolver) fillVariableType(variable *types.Variable, spec *ast.ValueSpec) {
+	if spec.Type != nil {
+		variable.Type = r.exprString(spec.Type)
+	}
+}
+
+// Flattens a field list so `a, b int` becomes two variables. Unnamed params are kept without
+// a name, while unnamed struct fields are embedded and named after their type
+func (r *Resolver) fieldVariables(list *ast.FieldList, isStruct bool) []types.Variable {
+	var vars []types.Variable
+	if list == nil {
+		return vars
+	}
+
+	for _, field := range list.List {
+		typ := r.exprString(field.Type)
+		if len(field.Names) == 0 {
+			name := ""
+			if isStruct {
+				name = EmbeddedName(field.Type)
+			}
+			vars = append(vars, types.Variable{Name: name, Type: typ, Exported: ast.IsExported(name)})
+			continue
+		}
+
+		for _, ident := range field.Names {
+			vars = append(vars, types.Variable{Name: ident.Name, Type: typ, Exported: ast.IsExported(ident.Name)})
+		}
+	}
+
+	return vars
+}
+
+func (r *Resolver) exprString(expr ast.Expr) string {
+	return ExprString(r.fset, expr)
+}
+
+// ExprString formats a type expression the way it is written in the source
+func ExprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+// EmbeddedName returns the type name of an embedded struct field, eg. `Mutex` for `*sync.Mutex`
+func EmbeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return EmbeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return EmbeddedName(e.X)
+	case *ast.IndexListExpr:
+		return EmbeddedName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+func findVariable(vars []types.Variable, name string) *types.Variable {
+	if name == "" {
+		return nil
+	}
+	for i := range vars {
+		if vars[i].Name == name {
+			return &vars[i]
+		}
+	}
+	return nil
+}
+
+// Named returns are matched by name, unnamed returns by their position
+func findReturn(rets []types.ReturnValue, name string, index int) *types.ReturnValue {
+	if name != "" {
+		for i := range rets {
+			if rets[i].Name == name {
+				return &rets[i]
+			}
+		}
+	}
+	if index < len(rets) && (rets[index].Name == "" || rets[index].Name == name) {
+		return &rets[index]
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+const signatureSource = `package demo
+
+import "sync"
+
+type Pair struct {
+	A, B int
+	sync.Mutex
+}
+
+func Div(a, b int) (q int, err error) { return a / b, nil }
+`
+
+func TestFillSignature(t *testing.T) {
+	r := New()
+	r.Sources = map[string][]byte{"demo.go": []byte(signatureSource)}
+	file := types.File{
+		Path: "demo.go",
+		// Documented names and types that are wrong or missing are replaced, descriptions are kept
+		Types: []types.Type{{Name: "Pair", Pos: pos(4), Fields: []types.Variable{
+			{Name: "B", Type: "string", Desc: "Second"},
+			{Name: "C", Desc: "Removed"},
+		}}},
+		Funcs: []types.Function{{Name: "Div", Pos: pos(9),
+			Params: []types.Variable{{Name: "b", Type: "float64", Desc: "Divisor"}, {Name: "c", Desc: "Removed"}},
+			Returns: []types.ReturnValue{
+				{Variable: types.Variable{Desc: "Quotient"}},
+				{Variable: types.Variable{Name: "err", Desc: "When b is zero"}},
+			},
+		}},
+	}
+	r.ResolveFile(&file)
+	if len(r.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics %v", r.Diagnostics)
+	}
+
+	wantFields := []types.Variable{
+		{Name: "A", Type: "int", Exported: true},
+		{Name: "B", Type: "int", Desc: "Second", Exported: true},
+		{Name: "Mutex", Type: "sync.Mutex", Exported: true},
+	}
+	if got := file.Types[0].Fields; !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("got fields %+v, want %+v", got, wantFields)
+	}
+
+	function := file.Funcs[0]
+	wantParams := []types.Variable{{Name: "a", Type: "int"}, {Name: "b", Type: "int", Desc: "Divisor"}}
+	if !reflect.DeepEqual(function.Params, wantParams) {
+		t.Errorf("got params %+v, want %+v", function.Params, wantParams)
+	}
+	wantReturns := []types.ReturnValue{
+		{Variable: types.Variable{Name: "q", Type: "int", Desc: "Quotient"}},
+		{Variable: types.Variable{Name: "err", Type: "error", Desc: "When b is zero"}, IsError: true},
+	}
+	if !reflect.DeepEqual(function.Returns, wantReturns) {
+		t.Errorf("got returns %+v, want %+v", function.Returns, wantReturns)
+	}
+	if !function.Exported || function.Receiver != nil {
+		t.Errorf("got exported %t and receiver %v, want an exported function", function.Exported, function.Receiver)
+	}
+
+	// The binding keeps what the block wrote for linting
+	if doc := r.Bindings[1].Doc; len(doc.Params) != 2 || doc.Params[0].Type != "float64" {
+		t.Errorf("binding lost the documented params: %+v", doc.Params)
+	}
+}
@@ -0,0 +1,331 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Kind is the kind of value a tag holds
+type Kind string
+
+const (
+	KindName       Kind = "name"
+	KindText       Kind = "text"
+	KindType       Kind = "type"
+	KindLink       Kind = "link"
+	KindCode       Kind = "code"
+	KindSignature  Kind = "signature"
+	KindResponse   Kind = "response"
+	KindDependency Kind = "dependency"
+)
+
+// How a value of each kind is written
+var kindSyntax = map[Kind]string{
+	KindName:       "Name",
+	KindText:       "Any text",
+	KindType:       "Go type, eg. `map[string]int`",
+	KindLink:       "URL",
+	KindCode:       "Go code",
+	KindSignature:  "`name (Type): Description`, any part can be left out and the type is any Go type",
+	KindResponse:   "`404 Description`",
+	KindDependency: "`(Name) Description`, or a block statement",
+}
+
+// Syntax describes how a value of the kind is written
+func (k Kind) Syntax() string {
+	return kindSyntax[k]
+}
+
+// Cardinality is how many times a tag may be given in a block
+type Cardinality int
+
+const (
+	Optional Cardinality = iota // At most once, a repeated tag overrides the earlier one
+	Required                    // At least once; repeats warn
+	Many                        // Any number of times
+)
+
+func (c Cardinality) String() string {
+	switch c {
+	case Required:
+		return "required"
+	case Many:
+		return "repeatable"
+	}
+	return "optional"
+}
+
+// Tag describes a tag, Tags is set when it can be written as a block statement
+type Tag struct {
+	Name        string
+	Aliases     []string
+	Doc         string
+	Kind        Kind
+	Cardinality Cardinality
+	Tags        []Tag
+	Label       string // Only set on custom tags, which are kept as metadata
+}
+
+// Header describes a block's header and the tags it accepts
+type Header struct {
+	Name    string
+	Aliases []string
+	Doc     string
+	Tags    []Tag
+}
+
+// Canonical header names
+const (
+	HeaderPackage  = "PKG"
+	HeaderFile     = "FILE"
+	HeaderType     = "TYPE"
+	HeaderVariable = "VAR"
+	HeaderFunction = "FUNC"
+)
+
+// Canonical tag names, the names a tag is written with are mapped to these
+const (
+	TagPackage     = "pkg"
+	TagFile        = "file"
+	TagType        = "type"
+	TagVariable    = "var"
+	TagFunction    = "func"
+	TagName        = "name"
+	TagDescription = "description"
+	TagUsage       = "usage"
+	TagDependency  = "dependency"
+	TagAuthor      = "author"
+	TagVersion     = "version"
+	TagDate        = "date"
+	TagField       = "field"
+	TagParam       = "param"
+	TagReturn      = "return"
+	TagReceiver    = "receiver"
+	TagResponse    = "response"
+	TagExample     = "example"
+	TagLink        = "link"
+	TagImport      = "import"
+)
+
+var description = Tag{Name: TagDescription, Aliases: []string{"desc", "d"}, Kind: KindText, Doc: "What it is and what it's for."}
+
+// DependencyTags are the tags of a `@dep { ... }` block statement
+var DependencyTags = []Tag{
+	{Name: TagName, Aliases: []string{"n"}, Kind: KindName, Cardinality: Required, Doc: "Name of the dependency."},
+	description,
+	{Name: TagLink, Aliases: []string{"l"}, Kind: KindLink, Doc: "Link to the dependency's page."},
+	{Name: TagImport, Aliases: []string{"i"}, Kind: KindText, Doc: "Import path of the dependency, eg. `github.com/user/dependency`."},
+}
+
+var dependency = Tag{Name: TagDependency, Aliases: []string{"dep"}, Kind: KindDependency, Cardinality: Many, Tags: DependencyTags, Doc: "A dependency."}
+
+// Headers lists every header in the order they're documented
+var Headers = []Header{
+	{
+		Name:    HeaderPackage,
+		Aliases: []string{"PACKAGE"},
+		Doc:     "Describes the high-level package information.",
+		Tags: []Tag{
+			{Name: TagPackage, Aliases: []string{"package", "name", "n", "p"}, Kind: KindName, Doc: "Name of the package, must match its package clause."},
+			description,
+			{Name: TagUsage, Aliases: []string{"u"}, Kind: KindText, Doc: "How the package is meant to be used."},
+			dependency,
+		},
+	},
+	{
+		Name: HeaderFile,
+		Doc:  "Describes the high-level information of a file.",
+		Tags: []Tag{
+			{Name: TagFile, Aliases: []string{"name", "n", "f"}, Kind: KindName, Doc: "Name the file is shown with, its base name by default."},
+			description,
+			{Name: TagAuthor, Aliases: []string{"auth", "a"}, Kind: KindText, Doc: "Who wrote the file."},
+			{Name: TagVersion, Aliases: []string{"v"}, Kind: KindText, Doc: "Version of the file."},
+			{Name: TagDate, Kind: KindText, Doc: "When the file was written or last changed."},
+			dependency,
+		},
+	},
+	{
+		Name: HeaderType,
+		Doc:  "Documents a type declaration.",
+		Tags: []Tag{
+			{Name: TagType, Aliases: []string{"name", "n", "t"}, Kind: KindName, Cardinality: Required, Doc: "Name of the type."},
+			description,
+			{Name: TagField, Aliases: []string{"f"}, Kind: KindSignature, Cardinality: Many, Doc: "A struct field, its type is read from the declaration."},
+		},
+	},
+	{
+		Name:    HeaderVariable,
+		Aliases: []string{"VARIABLE"},
+		Doc:     "Documents a variable or constant.",
+		Tags: []Tag{
+			{Name: TagVariable, Aliases: []string{"variable", "name", "n", "v"}, Kind: KindName, Cardinality: Required, Doc: "Name of the variable or constant."},
+			{Name: TagType, Aliases: []string{"t"}, Kind: KindType, Doc: "Type of the variable, read from the declaration when it has one."},
+			description,
+		},
+	},
+	{
+		Name:    HeaderFunction,
+		Aliases: []string{"FUNCTION"},
+		Doc:     "Documents a function or method.",
+		Tags: []Tag{
+			{Name: TagFunction, Aliases: []string{"function", "name", "n"}, Kind: KindName, Cardinality: Required, Doc: "Name of the function, methods may include their receiver, eg. `(h *Handler) Serve`."},
+			description,
+			{Name: TagParam, Aliases: []string{"p"}, Kind: KindSignature, Cardinality: Many, Doc: "A parameter, its type is read from the declaration."},
+			{Name: TagReturn, Aliases: []string{"ret", "r"}, Kind: KindSignature, Cardinality: Many, Doc: "A return value, its type is read from the declaration."},
+			{Name: TagReceiver, Aliases: []string{"rec"}, Kind: KindName, Doc: "Receiver type of a method."},
+			{Name: TagResponse, Aliases: []string{"res"}, Kind: KindResponse, Cardinality: Many, Doc: "An HTTP response the handler writes."},
+			{Name: TagExample, Aliases: []string{"ex"}, Kind: KindCode, Cardinality: Many, Doc: "Example code."},
+		},
+	},
+}
+
+// Extend returns the headers with the custom tags from the settings added to the headers they
+// apply to. Tags that can't be added are skipped and reported
+func Extend(custom []types.CustomTag) ([]Header, []error) {
+	if len(custom) == 0 {
+		return Headers, nil
+	}
+
+	headers := make([]Header, len(Headers))
+	for i, h := range Headers {
+		headers[i] = h
+		headers[i].Tags = append([]Tag(nil), h.Tags...)
+	}
+
+	var errs []error
+	for i, c := range custom {
+		tag, err := customTag(c)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("entry %d %v", i+1, err))
+			continue
+		}
+
+		applies := make([]bool, len(headers))
+		for _, name := range c.Headers {
+			h, ok := lookupHeader(headers, name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("`@%s` names unknown header `%s`", c.Name, name))
+				continue
+			}
+			applies[h] = true
+		}
+
+		var conflicts []string
+		for h := range headers {
+			if len(c.Headers) > 0 && !applies[h] {
+				continue
+			}
+			if name, ok := conflict(headers[h].Tags, tag); ok {
+				conflicts = append(conflicts, fmt.Sprintf("`@%s` of `%s`", name, headers[h].Name))
+				continue
+			}
+			headers[h].Tags = append(headers[h].Tags, tag)
+		}
+		if conflicts != nil {
+			errs = append(errs, fmt.Errorf("`@%s` conflicts with %s", c.Name, strings.Join(conflicts, ", ")))
+		}
+	}
+
+	return headers, errs
+}
+
+func customTag(c types.CustomTag) (Tag, error) {
+	for _, name := range append([]string{c.Name}, c.Aliases...) {
+		if !validName(name) {
+			return Tag{}, fmt.Errorf("has invalid name `%s`, use letters, digits, `-` and `_`", name)
+		}
+	}
+
+	tag := Tag{Name: c.Name, Aliases: c.Aliases, Kind: KindText, Label: c.Label}
+	if tag.Label == "" {
+		tag.Label = c.Name
+	}
+	tag.Doc = fmt.Sprintf("Custom tag, shown as %s.", tag.Label)
+	if c.Repeatable {
+		tag.Cardinality = Many
+	}
+	return tag, nil
+}
+
+// The name of the header's tag that one of the custom tag's names collides with
+func conflict(tags []Tag, tag Tag) (string, bool) {
+	for _, name := range tag.Names() {
+		existing, ok := Lookup(tags, name)
+		if !ok {
+			continue
+		}
+		for _, n := range existing.Names() {
+			if strings.EqualFold(n, name) {
+				return n, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Tag names are read up to the first space, the rest keeps them easy to type and link to
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r != '-' && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find finds a header in the list by its name or an alias, ignoring case
+func Find(headers []Header, name string) (*Header, bool) {
+	i, ok := lookupHeader(headers, name)
+	if !ok {
+		return nil, false
+	}
+	return &headers[i], true
+}
+
+func lookupHeader(headers []Header, name string) (int, bool) {
+	name = strings.TrimSpace(name)
+	for i := range headers {
+		if matches(headers[i].Name, headers[i].Aliases, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Lookup finds a tag the header accepts by its name or an alias, ignoring case
+func (h *Header) Lookup(name string) (*Tag, bool) {
+	return Lookup(h.Tags, name)
+}
+
+// Lookup finds a tag in the list by its name or an alias, ignoring case
+func Lookup(tags []Tag, name string) (*Tag, bool) {
+	for i := range tags {
+		if matches(tags[i].Name, tags[i].Aliases, name) {
+			return &tags[i], true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the tag's name followed by its aliases
+func (t *Tag) Names() []string {
+	return append([]string{t.Name}, t.Aliases...)
+}
+
+func matches(name string, aliases []string, s string) bool {
+	if strings.EqualFold(name, s) {
+		return true
+	}
+	for _, alias := range aliases {
+		if strings.EqualFold(alias, s) {
+			return true
+		}
+	}
+	return false
+}
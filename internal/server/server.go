@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+)
+
+// Path the pages listen on for reloads
+const EventsPath = "/_docmate/events"
+
+// Added to every page so open tabs reload when the site is rebuilt
+const reloadScript = `<script>new EventSource("` + EventsPath + `").addEventListener("reload", () => location.reload());</script>`
+
+// Server serves the pages of the HTML site from memory and tells open pages to reload when
+// they're replaced
+type Server struct {
+	mu      sync.RWMutex
+	pages   map[string][]byte
+	clients map[chan struct{}]bool
+	closed  bool
+}
+
+func New() *Server {
+	return &Server{pages: make(map[string][]byte), clients: make(map[chan struct{}]bool)}
+}
+
+// SetPages replaces the site, keyed by file name as returned by generator.RenderHTML, and
+// reloads every open page
+func (s *Server) SetPages(pages map[string][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pages = pages
+	for client := range s.clients {
+		// A client that hasn't handled the last reload yet will reload anyway
+		select {
+		case client <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close ends every open event stream so the HTTP server can shut down
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for client := range s.clients {
+		close(client)
+		delete(s.clients, client)
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == EventsPath {
+		s.serveEvents(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := path.Base(r.URL.Path)
+	if r.URL.Path == "/" {
+		name = "index.html"
+	}
+
+	s.mu.RLock()
+	page, ok := s.pages[name]
+	s.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(withReloadScript(page))
+}
+
+// Streams a `reload` event every time the pages are replaced
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan struct{}, 1)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		http.Error(w, "server closed", http.StatusServiceUnavailable)
+		return
+	}
+	s.clients[client] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case _, ok := <-client:
+			if !ok {
+				return
+			}
+			fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// The script goes before `</body>`, or at the end of pages a custom template left without one
+func withReloadScript(page []byte) []byte {
+	i := bytes.LastIndex(page, []byte("</body>"))
+	if i < 0 {
+		i = len(page)
+	}
+
+	out := make([]byte, 0, len(page)+len(reloadScript))
+	out = append(out, page[:i]...)
+	out = append(out, reloadScript...)
+	return append(out, page[i:]...)
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServePages(t *testing.T) {
+	s := New()
+	s.SetPages(map[string][]byte{
+		"index.html":   []byte("<html><body><h1>Index</h1></body></html>"),
+		"pkg-cmd.html": []byte("<h1>No body</h1>"),
+	})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		want   string
+	}{
+		{name: "root", path: "/", status: http.StatusOK, want: "<html><body><h1>Index</h1>" + reloadScript + "</body></html>"},
+		{name: "page", path: "/index.html", status: http.StatusOK, want: "<html><body><h1>Index</h1>" + reloadScript + "</body></html>"},
+		{name: "page without a body", path: "/pkg-cmd.html", status: http.StatusOK, want: "<h1>No body</h1>" + reloadScript},
+		{name: "missing page", path: "/pkg-missing.html", status: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		resp, err := http.Get(ts.URL + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != test.status {
+			t.Errorf("%s: got status %d, want %d", test.name, resp.StatusCode, test.status)
+		}
+		if test.want != "" && string(body) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, body, test.want)
+		}
+	}
+}
+
+// Opens an event stream, the client is registered by the time the response arrives
+func openEvents(t *testing.T, ts *httptest.Server) *http.Response {
+	t.Helper()
+	resp, err := http.Get(ts.URL + EventsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	return resp
+}
+
+// Reads a line of the stream, failing when none arrives in time. Returns false at the end
+func readLine(t *testing.T, r *bufio.Reader) (string, bool) {
+	t.Helper()
+	type result struct {
+		line string
+		err  error
+	}
+	lines := make(chan result, 1)
+	go func() {
+		line, err := r.ReadString('\n')
+		lines <- result{line, err}
+	}()
+
+	select {
+	case got := <-lines:
+		if got.err == io.EOF {
+			return "", false
+		}
+		if got.err != nil {
+			t.Fatal(got.err)
+		}
+		return got.line, true
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the event stream")
+		return "", false
+	}
+}
+
+func TestSetPagesReloads(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	defer s.Close()
+
+	resp := openEvents(t, ts)
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("got Content-Type %q, want text/event-stream", got)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	for i := range 2 {
+		s.SetPages(map[string][]byte{"index.html": []byte("<h1>Index</h1>")})
+		var event []string
+		for {
+			line, ok := readLine(t, r)
+			if !ok {
+				t.Fatalf("reload %d: stream ended", i+1)
+			}
+			if line == "\n" {
+				break
+			}
+			event = append(event, line)
+		}
+		if got := strings.Join(event, ""); got != "event: reload\ndata: {}\n" {
+			t.Errorf("reload %d: got %q, want a reload event", i+1, got)
+		}
+	}
+}
+
+func TestCloseEndsStreams(t *testing.T) {
+	s := New()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp := openEvents(t, ts)
+	defer resp.Body.Close()
+
+	s.Close()
+	if line, ok := readLine(t, bufio.NewReader(resp.Body)); ok {
+		t.Errorf("got %q, want the stream to end", line)
+	}
+
+	// Streams opened after closing are refused
+	resp, err := http.Get(ts.URL + EventsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got status %d after closing, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,87 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Bump whenever the layout of the saved data changes in a way older loaders can't read
+const SchemaVersion = 1
+
+// Name of the save data file inside the output path
+const FileName = "docmate.json"
+
+// Snapshot is the save data written by `docmate save`
+type Snapshot struct {
+	SchemaVersion int             `json:"schema_version"`
+	ProjectName   string          `json:"project_name"`
+	ProjectDesc   string          `json:"project_description,omitempty"`
+	ImgLink       string          `json:"image_link,omitempty"`
+	Packages      []types.Package `json:"packages"`
+}
+
+// Save writes the documentation tree to the save data file in the output path
+func Save(pkgs []types.Package, settings *types.Settings) (string, error) {
+	snap := Snapshot{
+		SchemaVersion: SchemaVersion,
+		ProjectName:   settings.ProjectName,
+		ProjectDesc:   settings.ProjectDesc,
+		ImgLink:       settings.ImgLink,
+		Packages:      pkgs,
+	}
+
+	// Descriptions commonly hold HTML (eg. `<u>`), so keep it readable rather than escaped
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(snap); err != nil {
+		return "", fmt.Errorf("failed to marshal save data: %v", err)
+	}
+	content := buf.Bytes()
+
+	if err := os.MkdirAll(settings.OutputPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	outputPath := filepath.Join(settings.OutputPath, FileName)
+	if err := os.WriteFile(outputPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %v", outputPath, err)
+	}
+
+	return outputPath, nil
+}
+
+// Load reads save data previously written by Save
+func Load(path string) (*Snapshot, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read save data: %v", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(content, &snap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal save data: %v", err)
+	}
+
+	if snap.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("unsupported save data schema version %d (expected %d), run `docmate save` again", snap.SchemaVersion, SchemaVersion)
+	}
+
+	return &snap, nil
+}
+
+// Settings returns a copy of the loaded settings with the project described by the snapshot, so
+// the rest, eg. Template_Dir and Custom_Tags_Title, still apply when generating from it
+func (s *Snapshot) Settings(loaded *types.Settings) *types.Settings {
+	settings := *loaded
+	settings.ProjectName = s.ProjectName
+	settings.ProjectDesc = s.ProjectDesc
+	settings.ImgLink = s.ImgLink
+	return &settings
+}
@@ -0,0 +1,115 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/generator"
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+func TestSaveLoad(t *testing.T) {
+	settings := &types.Settings{ProjectName: "Demo", ProjectDesc: "A <u>demo</u>", OutputPath: t.TempDir()}
+	pkgs := []types.Package{{
+		Name: "demo",
+		Desc: "Demo package",
+		Files: []types.File{{
+			Path:  "demo/demo.go",
+			Name:  "demo.go",
+			Funcs: []types.Function{{Name: "Add", Exported: true, Pos: types.Position{Filepath: "demo/demo.go", Line: 3, Column: 1}}},
+		}},
+	}}
+
+	path, err := Save(pkgs, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Zero positions are left out and HTML isn't escaped
+	if strings.Count(string(content), `"pos"`) != 1 {
+		t.Errorf("save data should only hold the function's position:\n%s", content)
+	}
+	if !strings.Contains(string(content), "A <u>demo</u>") {
+		t.Errorf("save data escaped the description:\n%s", content)
+	}
+
+	snap, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snap.Packages, pkgs) {
+		t.Errorf("got packages %+v, want %+v", snap.Packages, pkgs)
+	}
+	loaded := &types.Settings{ProjectName: "Other", OutputPath: "out", TemplateDir: "templates", CustomTagsTitle: "Ownership"}
+	want := types.Settings{ProjectName: "Demo", ProjectDesc: settings.ProjectDesc, OutputPath: "out", TemplateDir: "templates", CustomTagsTitle: "Ownership"}
+	if got := snap.Settings(loaded); !reflect.DeepEqual(*got, want) {
+		t.Errorf("got settings %+v, want %+v", *got, want)
+	}
+	if loaded.ProjectName != "Other" {
+		t.Errorf("the loaded settings were changed")
+	}
+
+	// Saving the same tree again writes the same bytes
+	again, err := Save(pkgs, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := os.ReadFile(again); string(second) != string(content) {
+		t.Errorf("save data changed between runs")
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(`{"schema_version": 0, "project_name": "Old", "packages": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "unsupported save data schema version 0") {
+		t.Errorf("got error %v, want a schema version mismatch", err)
+	}
+}
+
+// Generating from save data renders custom tags the same way as generating from the project
+func TestGenerateFromSnapshot(t *testing.T) {
+	settings := &types.Settings{ProjectName: "Demo", OutputPath: t.TempDir(), CustomTagsTitle: "Ownership"}
+	pkgs := []types.Package{{
+		Name:  "demo",
+		Meta:  []types.Metadata{{Key: "owner", Label: "Owner", Value: "platform-team"}},
+		Funcs: []types.Function{{Name: "Add", Meta: []types.Metadata{{Key: "owner", Label: "Owner", Value: "alice"}}}},
+	}}
+	direct, err := generator.New(pkgs, settings).RenderMarkdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := Save(pkgs, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &types.Settings{OutputPath: settings.OutputPath, CustomTagsTitle: "Ownership"}
+	fromSnapshot, err := generator.New(snap.Packages, snap.Settings(loaded)).RenderMarkdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(fromSnapshot) != string(direct) {
+		t.Errorf("got\n%s\nwant\n%s", fromSnapshot, direct)
+	}
+	for _, want := range []string{"- Ownership:\n    - Owner: platform-team\n", "    - Ownership:\n        - Owner: alice\n"} {
+		if !strings.Contains(string(fromSnapshot), want) {
+			t.Errorf("got\n%s\nwant it to contain %q", fromSnapshot, want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package types
+
+import "fmt"
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Stable diagnostic codes, never renumber these since users filter on them
+const (
+	CodeUnknownTag      = "DM001"
+	CodeUnknownHeader   = "DM002"
+	CodeMissingHeader   = "DM003"
+	CodeMissingName     = "DM004"
+	CodeUnclosedBlock   = "DM005"
+	CodeUnexpectedBrace = "DM006"
+	CodeBlockNotAllowed = "DM007"
+	CodeInvalidValue    = "DM008"
+	CodeNameMismatch    = "DM009"
+	CodeEmptyComment    = "DM010"
+	CodeUnterminated    = "DM011"
+	// Raised while binding blocks to Go declarations
+	CodeUnknownDeclaration = "DM012"
+	CodeSourceUnavailable  = "DM013"
+	// Raised when documentation no longer matches its declaration
+	CodeStaleTag         = "DM014"
+	CodeMissingDesc      = "DM015"
+	CodeTypeMismatch     = "DM016"
+	CodeReceiverMismatch = "DM017"
+	// Raised when a tag is given more often than its header allows
+	CodeDuplicateTag = "DM018"
+)
+
+// Diagnostic is an error or warning found while lexing or parsing comment blocks
+type Diagnostic struct {
+	Pos      Position `json:"pos"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+}
+
+// Formats the diagnostic as `file:line:col: severity CODE: message`
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s %s: %s", d.Pos, d.Severity, d.Code, d.Message)
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.Filepath, p.Line, p.Column)
+}
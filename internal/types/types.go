@@ -1,93 +1,129 @@
 package types
 
 type Settings struct {
-	ProjectName  string `json:"Project_Name"`
-	ProjectPath  string `json:"Project_Path"`
-	ProjectDesc  string `json:"Project_Description"`
-	ImgLink      string `json:"Image_Link"`
-	OutputPath   string `json:"Output_Path"`
-	IncludeTests bool   `json:"Include_Tests"`
-	CapitalizeItems bool `json:CapitalizeItems`
+	ProjectName     string      `json:"Project_Name"`
+	ProjectPath     string      `json:"Project_Path"`
+	ProjectDesc     string      `json:"Project_Description"`
+	ImgLink         string      `json:"Image_Link"`
+	OutputPath      string      `json:"Output_Path"`
+	IncludeTests    bool        `json:"Include_Tests"`
+	CapitalizeItems bool        `json:"CapitalizeItems"`
+	TemplateDir     string      `json:"Template_Dir"`      // Directory of templates overriding the default layouts
+	Include         []string    `json:"Include"`           // Globs of the files to read, every file when empty
+	Exclude         []string    `json:"Exclude"`           // Gitignore-style patterns of files and directories to skip
+	CustomTagsTitle string      `json:"Custom_Tags_Title"` // Heading of the section custom tags are shown in
+	CustomTags      []CustomTag `json:"Custom_Tags"`       // Tags DocMate doesn't know, kept as metadata
 }
 
-type Error struct {
-	Message string
-	Filepath string
-	Comment string
+// A tag declared in the settings, kept as metadata on the blocks of the headers it applies to
+type CustomTag struct {
+	Name       string   `json:"Name"`
+	Aliases    []string `json:"Aliases,omitempty"`
+	Headers    []string `json:"Headers,omitempty"` // Headers the tag may be used with, every header when empty
+	Repeatable bool     `json:"Repeatable,omitempty"`
+	Label      string   `json:"Label,omitempty"` // Shown in the documentation, the name when empty
 }
 
 type CommentBlock struct {
 	Filepath string
 	Package  string
 	Text     []string
+	Line     int // Line the comment block starts on
+	Column   int
+	TextPos  []Position // Position of each entry in Text
+}
+
+// Location of a comment block in the source code
+type Position struct {
+	Filepath string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
 }
 
 type Package struct {
-	Name  string
-	Desc  string
-	Usage string
-	Deps  []Dependancy
-	Files []File
-	Types []Type
-	Vars  []Variable
-	Funcs []Function
+	Name  string       `json:"name"`
+	Desc  string       `json:"desc,omitempty"`
+	Usage string       `json:"usage,omitempty"`
+	Deps  []Dependancy `json:"deps,omitempty"`
+	Files []File       `json:"files,omitempty"`
+	Types []Type       `json:"types,omitempty"`
+	Vars  []Variable   `json:"vars,omitempty"`
+	Funcs []Function   `json:"funcs,omitempty"`
+	Meta  []Metadata   `json:"meta,omitempty"`
+	Pos   Position     `json:"pos,omitzero"`
 }
 
 type Dependancy struct {
-	Name       string
-	Desc       string
-	Link       string
-	ImportPath string
+	Name       string `json:"name"`
+	Desc       string `json:"desc,omitempty"`
+	Link       string `json:"link,omitempty"`
+	ImportPath string `json:"import,omitempty"`
 }
 
 type File struct {
-	Path    string
-	Name    string
-	Desc    string
-	Auth    string
-	Version string
-	Date    string
-	Funcs   []Function
-	Vars    []Variable
-	Types   []Type
+	Path    string       `json:"path"`
+	Name    string       `json:"name"`
+	Desc    string       `json:"desc,omitempty"`
+	Auth    string       `json:"auth,omitempty"`
+	Version string       `json:"version,omitempty"`
+	Date    string       `json:"date,omitempty"`
+	Deps    []Dependancy `json:"deps,omitempty"`
+	Funcs   []Function   `json:"funcs,omitempty"`
+	Vars    []Variable   `json:"vars,omitempty"`
+	Types   []Type       `json:"types,omitempty"`
+	Meta    []Metadata   `json:"meta,omitempty"`
+	Pos     Position     `json:"pos,omitzero"`
 }
 
 type Type struct {
-	Name     string
-	Desc     string
-	Fields   []Variable
-	Exported bool
+	Name     string     `json:"name"`
+	Desc     string     `json:"desc,omitempty"`
+	Fields   []Variable `json:"fields,omitempty"`
+	Exported bool       `json:"exported"`
+	Meta     []Metadata `json:"meta,omitempty"`
+	Pos      Position   `json:"pos,omitzero"`
 }
 
 type Function struct {
-	Name      string
-	Desc      string
-	Params    []Variable
-	Returns   []ReturnValue
-	Responses []Response
-	Receiver  *Type
-	Examples  []Example
-	Exported  bool
+	Name      string        `json:"name"`
+	Desc      string        `json:"desc,omitempty"`
+	Params    []Variable    `json:"params,omitempty"`
+	Returns   []ReturnValue `json:"returns,omitempty"`
+	Responses []Response    `json:"responses,omitempty"`
+	Receiver  *Type         `json:"receiver,omitempty"`
+	Examples  []Example     `json:"examples,omitempty"`
+	Exported  bool          `json:"exported"`
+	Meta      []Metadata    `json:"meta,omitempty"`
+	Pos       Position      `json:"pos,omitzero"`
 }
 
 type ReturnValue struct {
 	Variable
-	IsError bool // Flag indicating whether this return value is an error
+	IsError bool `json:"is_error"` // Flag indicating whether this return value is an error
 }
 
 type Example struct {
-	Code string
-	Desc string // Description or explanation of the code
+	Code string `json:"code"`
+	Desc string `json:"desc,omitempty"` // Description or explanation of the code
 }
 
 type Variable struct {
-	Name     string
-	Type     string
-	Desc     string
-	Exported bool
+	Name     string     `json:"name"`
+	Type     string     `json:"type,omitempty"`
+	Desc     string     `json:"desc,omitempty"`
+	Exported bool       `json:"exported"`
+	Meta     []Metadata `json:"meta,omitempty"`
+	Pos      Position   `json:"pos,omitzero"`
 }
 
 type Response struct {
-	Code int // eg. 404, 200, 204 ...
-	Desc string
+	Code int    `json:"code"` // eg. 404, 200, 204 ...
+	Desc string `json:"desc,omitempty"`
+}
+
+// The value of a custom tag, in the order the tags were written
+type Metadata struct {
+	Key   string `json:"key"`   // Name of the custom tag
+	Label string `json:"label"` // How the tag is shown in the documentation
+	Value string `json:"value"`
 }
@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Source is where the effective value of a setting came from
+type Source string
+
+// Sources in increasing order of precedence
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Field is a setting that can be given in the settings file, a DOCMATE_* environment variable
+// or a command line flag
+type Field struct {
+	Key    string // Key in the settings file
+	Env    string
+	Flag   string
+	Usage  string
+	IsBool bool
+	IsPath bool // Relative paths in the settings file are relative to the file
+	IsList bool // Given as a comma-separated list in flags and environment variables
+	IsJSON bool // Structured, given as JSON in flags and environment variables
+	get    func(*types.Settings) string
+	set    func(*types.Settings, string) error
+	items  func(*types.Settings) *[]string // Only set for list fields
+}
+
+// Fields lists every field of types.Settings
+var Fields = []Field{
+	stringField("Project_Name", "project-name", "name shown as the title of the documentation", func(s *types.Settings) *string { return &s.ProjectName }),
+	pathField("Project_Path", "project", "path to the project to document", func(s *types.Settings) *string { return &s.ProjectPath }),
+	stringField("Project_Description", "description", "description shown under the title", func(s *types.Settings) *string { return &s.ProjectDesc }),
+	stringField("Image_Link", "image", "link to the project's image", func(s *types.Settings) *string { return &s.ImgLink }),
+	pathField("Output_Path", "output", "path documentation is written to", func(s *types.Settings) *string { return &s.OutputPath }),
+	boolField("Include_Tests", "include-tests", "read comments from _test.go files", func(s *types.Settings) *bool { return &s.IncludeTests }),
+	boolField("CapitalizeItems", "capitalize", "capitalize package and file names", func(s *types.Settings) *bool { return &s.CapitalizeItems }),
+	pathField("Template_Dir", "template-dir", "directory of templates overriding the default layouts", func(s *types.Settings) *string { return &s.TemplateDir }),
+	listField("Include", "include", "globs of the files to read", func(s *types.Settings) *[]string { return &s.Include }),
+	listField("Exclude", "exclude", "gitignore-style patterns of files and directories to skip", func(s *types.Settings) *[]string { return &s.Exclude }),
+	stringField("Custom_Tags_Title", "custom-tags-title", "heading of the section custom tags are shown in", func(s *types.Settings) *string { return &s.CustomTagsTitle }),
+	// Kept last, TOML writes it as an array of tables after every other key
+	jsonField("Custom_Tags", "custom-tags", "tags DocMate doesn't know, kept as metadata", func(s *types.Settings) *[]types.CustomTag { return &s.CustomTags }),
+}
+
+// Config is the merged settings along with the origin of every value
+type Config struct {
+	Settings *types.Settings
+	Path     string            // Settings file that was read, empty when there was none
+	Sources  map[string]Source // Keyed by Field.Key
+	keys     map[string]types.Position
+}
+
+// LoadConfig merges the defaults, the settings file at path, the DOCMATE_* environment variables
+// and the flags, keyed by Field.Key, with flags taking precedence. An empty path or a missing
+// settings file leaves Path empty
+func LoadConfig(path string, flags map[string]string) (*Config, error) {
+	settings := DefaultSettings()
+	config := &Config{Settings: &settings, Sources: make(map[string]Source)}
+	for _, field := range Fields {
+		config.Sources[field.Key] = SourceDefault
+	}
+
+	if path != "" {
+		keys, err := readSettingsFile(path, &settings)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			config.Path = path
+			config.keys = keys
+			for _, field := range Fields {
+				if !hasKeyIn(keys, field.Key) {
+					continue
+				}
+				config.Sources[field.Key] = SourceFile
+				if value := field.get(&settings); field.IsPath && value != "" && !filepath.IsAbs(value) {
+					field.set(&settings, filepath.Join(filepath.Dir(path), value))
+				}
+			}
+		}
+	}
+
+	for _, field := range Fields {
+		value, ok := os.LookupEnv(field.Env)
+		if !ok {
+			continue
+		}
+		if err := field.set(&settings, value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", field.Env, err)
+		}
+		config.Sources[field.Key] = SourceEnv
+	}
+
+	for _, field := range Fields {
+		value, ok := flags[field.Key]
+		if !ok {
+			continue
+		}
+		if err := field.set(&settings, value); err != nil {
+			return nil, fmt.Errorf("invalid -%s: %v", field.Flag, err)
+		}
+		config.Sources[field.Key] = SourceFlag
+	}
+
+	return config, nil
+}
+
+// Value returns the effective value of the field as text
+func (f Field) Value(settings *types.Settings) string {
+	return f.get(settings)
+}
+
+// Display returns the effective value of the field the way it is written in a settings file
+func (f Field) Display(settings *types.Settings) string {
+	switch {
+	case f.IsBool, f.IsJSON:
+		return f.get(settings)
+	case f.IsList:
+		quoted := []string{}
+		for _, item := range *f.items(settings) {
+			quoted = append(quoted, strconv.Quote(item))
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+	return strconv.Quote(f.get(settings))
+}
+
+// Origin describes where the value of the field came from, eg. `env DOCMATE_OUTPUT_PATH`
+func (c *Config) Origin(field Field) string {
+	switch c.Sources[field.Key] {
+	case SourceFile:
+		return "file " + c.Path
+	case SourceEnv:
+		return "env " + field.Env
+	case SourceFlag:
+		return "flag -" + field.Flag
+	}
+	return "default"
+}
+
+func hasKeyIn(keys map[string]types.Position, key string) bool {
+	for k := range keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys match case-insensitively, the same way encoding/json matches them
+func hasKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Environment variables are named after the key, eg. DOCMATE_PROJECT_PATH for Project_Path
+func envName(key string) string {
+	var name strings.Builder
+	for i, r := range key {
+		if i > 0 && r >= 'A' && r <= 'Z' && key[i-1] >= 'a' && key[i-1] <= 'z' {
+			name.WriteByte('_')
+		}
+		name.WriteRune(r)
+	}
+	return "DOCMATE_" + strings.ToUpper(name.String())
+}
+
+func stringField(key, flag, usage string, ptr func(*types.Settings) *string) Field {
+	return Field{
+		Key:   key,
+		Env:   envName(key),
+		Flag:  flag,
+		Usage: usage,
+		get:   func(s *types.Settings) string { return *ptr(s) },
+		set: func(s *types.Settings, value string) error {
+			*ptr(s) = value
+			return nil
+		},
+	}
+}
+
+func pathField(key, flag, usage string, ptr func(*types.Settings) *string) Field {
+	field := stringField(key, flag, usage, ptr)
+	field.IsPath = true
+	return field
+}
+
+func listField(key, flag, usage string, ptr func(*types.Settings) *[]string) Field {
+	return Field{
+		Key:    key,
+		Env:    envName(key),
+		Flag:   flag,
+		Usage:  usage,
+		IsList: true,
+		get:    func(s *types.Settings) string { return strings.Join(*ptr(s), ",") },
+		set: func(s *types.Settings, value string) error {
+			items := []string{}
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			*ptr(s) = items
+			return nil
+		},
+		items: ptr,
+	}
+}
+
+func jsonField[T any](key, flag, usage string, ptr func(*types.Settings) *T) Field {
+	return Field{
+		Key:    key,
+		Env:    envName(key),
+		Flag:   flag,
+		Usage:  usage,
+		IsJSON: true,
+		get: func(s *types.Settings) string {
+			b, _ := json.Marshal(*ptr(s))
+			return string(b)
+		},
+		set: func(s *types.Settings, value string) error {
+			// Catches misspelled keys, which would otherwise be silently dropped
+			var v T
+			dec := json.NewDecoder(strings.NewReader(value))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&v); err != nil {
+				return fmt.Errorf("is invalid: %v", err)
+			}
+			*ptr(s) = v
+			return nil
+		},
+	}
+}
+
+func boolField(key, flag, usage string, ptr func(*types.Settings) *bool) Field {
+	return Field{
+		Key:    key,
+		Env:    envName(key),
+		Flag:   flag,
+		Usage:  usage,
+		IsBool: true,
+		get:    func(s *types.Settings) string { return strconv.FormatBool(*ptr(s)) },
+		set: func(s *types.Settings, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a boolean", value)
+			}
+			*ptr(s) = b
+			return nil
+		},
+	}
+}
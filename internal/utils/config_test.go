@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Clears every DOCMATE_* variable for the test, restoring them afterwards
+func clearEnv(t *testing.T) {
+	for _, field := range Fields {
+		t.Setenv(field.Env, "")
+		os.Unsetenv(field.Env)
+	}
+}
+
+func fieldByKey(t *testing.T, key string) Field {
+	for _, field := range Fields {
+		if field.Key == key {
+			return field
+		}
+	}
+	t.Fatalf("no field %s", key)
+	return Field{}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	content := `{"Project_Name": "From file", "Output_Path": "docs", "Include_Tests": true, "Exclude": ["vendor/"]}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		file   bool
+		env    map[string]string
+		flags  map[string]string
+		key    string
+		want   string
+		origin string
+	}{
+		{name: "default", key: "Project_Name", want: "Include project name here...", origin: "default"},
+		{name: "missing file", key: "Project_Name", want: "Include project name here...", origin: "default"},
+		{name: "file over default", file: true, key: "Project_Name", want: "From file", origin: "file " + path},
+		{name: "unset key keeps the default", file: true, key: "Custom_Tags_Title", want: "Details", origin: "default"},
+		{name: "file paths are relative to the file", file: true, key: "Output_Path", want: filepath.Join(dir, "docs"), origin: "file " + path},
+		{
+			name: "env over file", file: true,
+			env: map[string]string{"DOCMATE_PROJECT_NAME": "From env"},
+			key: "Project_Name", want: "From env", origin: "env DOCMATE_PROJECT_NAME",
+		},
+		{
+			name: "env paths are kept as given", file: true,
+			env: map[string]string{"DOCMATE_OUTPUT_PATH": "out"},
+			key: "Output_Path", want: "out", origin: "env DOCMATE_OUTPUT_PATH",
+		},
+		{
+			name: "flag over env", file: true,
+			env:   map[string]string{"DOCMATE_PROJECT_NAME": "From env"},
+			flags: map[string]string{"Project_Name": "From flag"},
+			key:   "Project_Name", want: "From flag", origin: "flag -project-name",
+		},
+		{
+			name:  "flag over default",
+			flags: map[string]string{"Include_Tests": "true"},
+			key:   "Include_Tests", want: "true", origin: "flag -include-tests",
+		},
+		{
+			name: "env list over file", file: true,
+			env: map[string]string{"DOCMATE_EXCLUDE": "testdata/, *.pb.go"},
+			key: "Exclude", want: "testdata/,*.pb.go", origin: "env DOCMATE_EXCLUDE",
+		},
+		{
+			name: "env bool over file", file: true,
+			env: map[string]string{"DOCMATE_INCLUDE_TESTS": "false"},
+			key: "Include_Tests", want: "false", origin: "env DOCMATE_INCLUDE_TESTS",
+		},
+	}
+
+	for _, test := range tests {
+		clearEnv(t)
+		for name, value := range test.env {
+			t.Setenv(name, value)
+		}
+		settingsPath := filepath.Join(dir, "missing.json")
+		if test.file {
+			settingsPath = path
+		}
+
+		config, err := LoadConfig(settingsPath, test.flags)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		field := fieldByKey(t, test.key)
+		if got := field.Value(config.Settings); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+		if got := config.Origin(field); got != test.origin {
+			t.Errorf("%s: got origin %q, want %q", test.name, got, test.origin)
+		}
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		flags map[string]string
+		want  string
+	}{
+		{name: "env bool", env: map[string]string{"DOCMATE_INCLUDE_TESTS": "maybe"}, want: `invalid DOCMATE_INCLUDE_TESTS: "maybe" is not a boolean`},
+		{name: "flag bool", flags: map[string]string{"CapitalizeItems": "maybe"}, want: `invalid -capitalize: "maybe" is not a boolean`},
+	}
+
+	for _, test := range tests {
+		clearEnv(t)
+		for name, value := range test.env {
+			t.Setenv(name, value)
+		}
+		_, err := LoadConfig("", test.flags)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.want)
+		}
+	}
+}
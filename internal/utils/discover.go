@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names a settings file is looked up by, in order of preference
+var SettingsFileNames = []string{DefaultSettingsPath, "docmate.yaml", "docmate.yml", "docmate.toml"}
+
+// FindSettings looks for a settings file in start and each of its parents, stopping at the
+// directory holding go.mod. It returns an empty path when there is none
+func FindSettings(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		for _, name := range SettingsFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return relative(path), nil
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Where discovery starts: the project path when it was given as a flag or environment variable,
+// otherwise the working directory
+func discoveryStart(flags map[string]string) string {
+	if path, ok := flags["Project_Path"]; ok && path != "" {
+		return path
+	}
+	if path := os.Getenv(envName("Project_Path")); path != "" {
+		return path
+	}
+	return "."
+}
+
+// DiscoverSettings finds the settings file for the project given by the flags, see FindSettings
+func DiscoverSettings(flags map[string]string) (string, error) {
+	return FindSettings(discoveryStart(flags))
+}
+
+// Paths under the working directory are shown relative to it
+func relative(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,321 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Settings file formats, detected by the file's extension
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// SettingsFileName returns the name `docmate init` gives a settings file of the format
+func SettingsFileName(format string) (string, error) {
+	switch format {
+	case FormatJSON:
+		return DefaultSettingsPath, nil
+	case FormatYAML, "yml":
+		return "docmate.yaml", nil
+	case FormatTOML:
+		return "docmate.toml", nil
+	}
+	return "", fmt.Errorf("unknown settings format `%s`, expected `json`, `yaml` or `toml`", format)
+}
+
+// Anything that isn't YAML or TOML is read as JSON, as settings files always were
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatJSON
+}
+
+// A top-level key of a settings file
+type entry struct {
+	key   string
+	value any
+	pos   types.Position
+}
+
+// Reads the settings file over the given settings, returning the position of every key
+func readSettingsFile(path string, settings *types.Settings) (map[string]types.Position, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var entries []entry
+	switch formatOf(path) {
+	case FormatYAML:
+		entries, err = yamlEntries(path, content)
+	case FormatTOML:
+		entries, err = tomlEntries(path, content)
+	default:
+		entries, err = jsonEntries(path, content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings file %s: %v", path, err)
+	}
+
+	keys := make(map[string]types.Position)
+	for _, e := range entries {
+		keys[e.key] = e.pos
+		for _, field := range Fields {
+			if !strings.EqualFold(field.Key, e.key) {
+				continue
+			}
+			if err := setFileValue(field, settings, e.value); err != nil {
+				return nil, fmt.Errorf("%s: %s %v", e.pos, field.Key, err)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// Settings files hold typed values, unlike flags and environment variables
+func setFileValue(field Field, settings *types.Settings, value any) error {
+	if field.IsBool {
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("must be true or false")
+		}
+		return field.set(settings, strconv.FormatBool(b))
+	}
+	if field.IsList {
+		return setFileList(field, settings, value)
+	}
+	if field.IsJSON {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return field.set(settings, string(b))
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("must be a string")
+	}
+	return field.set(settings, str)
+}
+
+// Lists are set item by item since a pattern may itself contain a comma
+func setFileList(field Field, settings *types.Settings, value any) error {
+	values, ok := value.([]any)
+	if !ok && value != nil {
+		return fmt.Errorf("must be a list of strings")
+	}
+
+	items := []string{}
+	for _, v := range values {
+		item, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("must be a list of strings")
+		}
+		items = append(items, item)
+	}
+	*field.items(settings) = items
+	return nil
+}
+
+func jsonEntries(path string, content []byte) ([]entry, error) {
+	var entries []entry
+	dec := json.NewDecoder(bytes.NewReader(content))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("settings must be an object")
+	}
+
+	for dec.More() {
+		start := skipSpace(content, int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key: key, value: value, pos: offsetPosition(path, content, start)})
+	}
+
+	return entries, nil
+}
+
+func yamlEntries(path string, content []byte) ([]entry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	// An empty file leaves every setting at its default
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("settings must be a mapping")
+	}
+
+	var entries []entry
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, node := mapping.Content[i], mapping.Content[i+1]
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{
+			key:   key.Value,
+			value: value,
+			pos:   types.Position{Filepath: path, Line: key.Line, Column: key.Column},
+		})
+	}
+
+	return entries, nil
+}
+
+func tomlEntries(path string, content []byte) ([]entry, error) {
+	var values map[string]any
+	md, err := toml.Decode(string(content), &values)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry
+	for _, key := range md.Keys() {
+		// Settings are flat, tables are kept whole so they're reported as unknown keys
+		if len(key) != 1 {
+			continue
+		}
+		entries = append(entries, entry{key: key[0], value: values[key[0]], pos: tomlKeyPosition(path, content, key[0])})
+	}
+
+	return entries, nil
+}
+
+// The TOML decoder doesn't expose key positions, so the key is found at the start of a line
+func tomlKeyPosition(path string, content []byte, key string) types.Position {
+	for i, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		for _, written := range []string{key, strconv.Quote(key), "'" + key + "'", "[" + key + "]", "[[" + key + "]]"} {
+			rest, ok := strings.CutPrefix(trimmed, written)
+			if ok && (strings.HasPrefix(strings.TrimLeft(rest, " \t"), "=") || written[0] == '[') {
+				return types.Position{Filepath: path, Line: i + 1, Column: len(line) - len(trimmed) + 1}
+			}
+		}
+	}
+	return types.Position{Filepath: path}
+}
+
+// The decoder's offset is before the comma separating keys
+func skipSpace(content []byte, offset int) int {
+	for offset < len(content) && strings.ContainsRune(" \t\r\n,", rune(content[offset])) {
+		offset++
+	}
+	return offset
+}
+
+func offsetPosition(path string, content []byte, offset int) types.Position {
+	line := 1 + bytes.Count(content[:offset], []byte("\n"))
+	column := offset - bytes.LastIndexByte(content[:offset], '\n')
+	return types.Position{Filepath: path, Line: line, Column: column}
+}
+
+// Encodes the settings in the format of path, keeping the order of Fields
+func encodeSettings(path string, settings *types.Settings) ([]byte, error) {
+	switch formatOf(path) {
+	case FormatYAML:
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+		for _, field := range Fields {
+			value := &yaml.Node{}
+			if err := value.Encode(fieldValue(field, settings)); err != nil {
+				return nil, err
+			}
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: field.Key}, value)
+		}
+		return yaml.Marshal(mapping)
+	case FormatTOML:
+		var buf bytes.Buffer
+		for _, field := range Fields {
+			if field.IsJSON {
+				if err := toml.NewEncoder(&buf).Encode(map[string]any{field.Key: fieldValue(field, settings)}); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			value := field.get(settings)
+			switch {
+			case field.IsList:
+				quoted := []string{}
+				for _, item := range *field.items(settings) {
+					quoted = append(quoted, tomlString(item))
+				}
+				value = "[" + strings.Join(quoted, ", ") + "]"
+			case !field.IsBool:
+				value = tomlString(value)
+			}
+			fmt.Fprintf(&buf, "%s = %s\n", field.Key, value)
+		}
+		return buf.Bytes(), nil
+	}
+	return json.MarshalIndent(settings, "", "  ")
+}
+
+func fieldValue(field Field, settings *types.Settings) any {
+	if field.IsBool {
+		return field.get(settings) == "true"
+	}
+	if field.IsList {
+		return *field.items(settings)
+	}
+	if field.IsJSON {
+		// Decoded generically so the keys keep the names they have in JSON
+		var value any
+		json.Unmarshal([]byte(field.get(settings)), &value)
+		return value
+	}
+	return field.get(settings)
+}
+
+// Quotes a TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
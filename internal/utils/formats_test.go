@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/DocMate/internal/types"
+)
+
+// Writes the settings the way `docmate init` does and reads them back, in every format
+func TestWriteLoad(t *testing.T) {
+	dir := t.TempDir()
+	full := types.Settings{
+		ProjectName:     `Demo "quoted" #1`,
+		ProjectPath:     filepath.Join(dir, "project"),
+		ProjectDesc:     "Line one: with a colon\nLine two",
+		ImgLink:         "https://example.com/logo.png?size=2&theme=dark",
+		OutputPath:      filepath.Join(dir, "docs"),
+		IncludeTests:    true,
+		CapitalizeItems: true,
+		TemplateDir:     filepath.Join(dir, "templates"),
+		Include:         []string{"**/*.go", "cmd/[a-z]*.go"},
+		Exclude:         []string{"vendor/", "!testdata/keep"},
+		CustomTagsTitle: "Ownership",
+		CustomTags: []types.CustomTag{
+			{Name: "owner", Aliases: []string{"maintainer"}, Headers: []string{"PKG", "FILE"}, Label: "Owned by"},
+			{Name: "since", Repeatable: true},
+		},
+	}
+
+	// Relative paths are read relative to the settings file
+	resolved := DefaultSettings()
+	resolved.ProjectPath, resolved.OutputPath = dir, dir
+
+	tests := []struct {
+		name     string
+		settings types.Settings
+		want     types.Settings
+	}{
+		{"defaults", DefaultSettings(), resolved},
+		{"every field set", full, full},
+	}
+
+	clearEnv(t)
+	for _, format := range []string{FormatJSON, FormatYAML, FormatTOML} {
+		for _, test := range tests {
+			name, err := SettingsFileName(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, name)
+			if err := WriteSettings(path, &test.settings); err != nil {
+				t.Fatalf("%s %s: %v", format, test.name, err)
+			}
+
+			config, err := LoadConfig(path, nil)
+			if err != nil {
+				t.Fatalf("%s %s: %v", format, test.name, err)
+			}
+			if !reflect.DeepEqual(*config.Settings, test.want) {
+				t.Errorf("%s %s: got %+v, want %+v", format, test.name, *config.Settings, test.want)
+			}
+			for _, field := range Fields {
+				if config.Sources[field.Key] != SourceFile {
+					t.Errorf("%s %s: %s came from %s, want the file", format, test.name, field.Key, config.Origin(field))
+				}
+			}
+			if errs := config.Validate(); test.name == "defaults" && len(errs) > 0 {
+				t.Errorf("%s %s: unexpected errors %v", format, test.name, errs)
+			}
+		}
+	}
+}
+
+// Keys after an array of tables would belong to its last table, so Custom_Tags is written last
+func TestWriteTOMLCustomTagsLast(t *testing.T) {
+	settings := DefaultSettings()
+	settings.CustomTags = []types.CustomTag{{Name: "owner"}, {Name: "since"}}
+	path := filepath.Join(t.TempDir(), "docmate.toml")
+	if err := WriteSettings(path, &settings); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := strings.Index(string(content), "[[Custom_Tags]]")
+	if tables == -1 || strings.Count(string(content), "[[Custom_Tags]]") != 2 {
+		t.Fatalf("got\n%s\nwant two [[Custom_Tags]] tables", content)
+	}
+	for _, field := range Fields {
+		if field.Key == "Custom_Tags" {
+			continue
+		}
+		if i := strings.Index(string(content), field.Key+" ="); i == -1 || i > tables {
+			t.Errorf("%s is missing or written after the custom tags in\n%s", field.Key, content)
+		}
+	}
+}
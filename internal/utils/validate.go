@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/ajtroup1/DocMate/internal/ignore"
+	"github.com/ajtroup1/DocMate/internal/schema"
+)
+
+// Validate reports unknown keys in the settings file and paths that can't be used. Each error
+// starts with where the offending value was given, eg. `settings.json:3:3: Project_Path ...`
+func (c *Config) Validate() []error {
+	var errs []error
+
+	var unknown []string
+	for key := range c.keys {
+		if !hasKey(fieldKeys(), key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool { return c.keys[unknown[i]].Line < c.keys[unknown[j]].Line })
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("%s: unknown key `%s`", c.keys[key], key))
+	}
+
+	for _, field := range Fields {
+		if field.Key == "Custom_Tags" {
+			_, tagErrs := schema.Extend(c.Settings.CustomTags)
+			for _, err := range tagErrs {
+				errs = append(errs, fmt.Errorf("%s: %s %v", c.where(field), field.Key, err))
+			}
+		}
+		if field.IsList {
+			for _, pattern := range *field.items(c.Settings) {
+				if err := ignore.Check(pattern); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %s %v", c.where(field), field.Key, err))
+				}
+			}
+		}
+		if !field.IsPath {
+			continue
+		}
+		value := field.Value(c.Settings)
+		var err error
+		switch field.Key {
+		case "Project_Path":
+			err = checkDir(value)
+		case "Output_Path":
+			err = checkWritable(value)
+		case "Template_Dir":
+			if value != "" {
+				err = checkDir(value)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s %v", c.where(field), field.Key, err))
+		}
+	}
+
+	return errs
+}
+
+// Position of the key in the settings file, or the variable or flag the value came from
+func (c *Config) where(field Field) string {
+	if c.Sources[field.Key] == SourceFile {
+		for key, pos := range c.keys {
+			if strings.EqualFold(key, field.Key) {
+				return pos.String()
+			}
+		}
+	}
+	return c.Origin(field)
+}
+
+func checkDir(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("`%s` does not exist", path)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("`%s` is not a directory", path)
+	}
+	return nil
+}
+
+// The output directory is created when documentation is written, so a missing directory only
+// needs a writable parent
+func checkWritable(path string) error {
+	dir := path
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("`%s` is not a directory", dir)
+			}
+			break
+		}
+		// A file in the path is reported once the walk reaches it
+		if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, syscall.ENOTDIR) {
+			return err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fmt.Errorf("`%s` can't be created", path)
+		}
+		dir = parent
+	}
+
+	// Permission bits don't account for read-only mounts or ACLs, so try to write a file
+	f, err := os.CreateTemp(dir, ".docmate-*")
+	if err != nil {
+		return fmt.Errorf("`%s` is not writable", dir)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
+
+func fieldKeys() []string {
+	keys := make([]string, len(Fields))
+	for i, field := range Fields {
+		keys[i] = field.Key
+	}
+	return keys
+}
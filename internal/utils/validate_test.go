@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "blocker"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string // Settings file, paths are relative to the temporary directory
+		want    []string
+	}{
+		{
+			name:    "valid",
+			content: `{"Project_Path": "./", "Output_Path": "docs", "Exclude": ["vendor/", "!testdata/"], "Custom_Tags": [{"Name": "owner"}]}`,
+		},
+		{
+			name:    "unknown keys in file order",
+			content: "{\n  \"Project_Name\": \"Demo\",\n  \"Project_Pth\": \"./\",\n    \"Outptu_Path\": \"docs\"\n}",
+			want:    []string{"settings.json:3:3: unknown key `Project_Pth`", "settings.json:4:5: unknown key `Outptu_Path`"},
+		},
+		{
+			name:    "missing project",
+			content: "{\n  \"Project_Path\": \"missing\"\n}",
+			want:    []string{"settings.json:2:3: Project_Path `missing` does not exist"},
+		},
+		{
+			name:    "project that is a file",
+			content: "{\n  \"Project_Path\": \"blocker\"\n}",
+			want:    []string{"settings.json:2:3: Project_Path `blocker` is not a directory"},
+		},
+		{
+			name:    "output below a file",
+			content: "{\n  \"Output_Path\": \"blocker/docs\"\n}",
+			want:    []string{"settings.json:2:3: Output_Path `blocker` is not a directory"},
+		},
+		{
+			name:    "bad globs",
+			content: "{\n  \"Include\": [\"**/*.go\", \"[abc\"],\n  \"Exclude\": [\"a\\\\\"]\n}",
+			want:    []string{"settings.json:2:3: Include invalid pattern `[abc`", "settings.json:3:3: Exclude invalid pattern `a\\`"},
+		},
+		{
+			name:    "custom tags that clash",
+			content: "{\n  \"Custom_Tags\": [{\"Name\": \"owner\"}, {\"Name\": \"Owner\"}, {\"Name\": \"see\", \"Aliases\": [\"desc\"], \"Headers\": [\"FUNC\"]}, {\"Name\": \"a b\"}, {\"Name\": \"since\", \"Headers\": [\"NOPE\"]}]\n}",
+			want: []string{
+				"settings.json:2:3: Custom_Tags `@Owner` conflicts with `@owner` of `PKG`, `@owner` of `FILE`, `@owner` of `TYPE`, `@owner` of `VAR`, `@owner` of `FUNC`",
+				"settings.json:2:3: Custom_Tags `@see` conflicts with `@desc` of `FUNC`",
+				"settings.json:2:3: Custom_Tags entry 4 has invalid name `a b`, use letters, digits, `-` and `_`",
+				"settings.json:2:3: Custom_Tags `@since` names unknown header `NOPE`",
+			},
+		},
+	}
+
+	clearEnv(t)
+	for _, test := range tests {
+		path := filepath.Join(dir, "settings.json")
+		if err := os.WriteFile(path, []byte(test.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		config, err := LoadConfig(path, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		var got []string
+		for _, err := range config.Validate() {
+			got = append(got, strings.ReplaceAll(err.Error(), dir+string(filepath.Separator), ""))
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// Values given as flags or environment variables are reported by where they came from
+func TestValidateOrigin(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DOCMATE_EXCLUDE", "[abc")
+	config, err := LoadConfig("", map[string]string{"Project_Path": filepath.Join(t.TempDir(), "missing")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := config.Validate()
+	if len(errs) != 2 || !strings.HasPrefix(errs[0].Error(), "flag -project: Project_Path") || errs[1].Error() != "env DOCMATE_EXCLUDE: Exclude invalid pattern `[abc`" {
+		t.Errorf("got %v, want the flag and the environment variable", errs)
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	dir := filepath.Join(t.TempDir(), "readonly")
+	if err := os.Mkdir(dir, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkWritable(filepath.Join(dir, "docs")); err == nil || !strings.Contains(err.Error(), "is not writable") {
+		t.Errorf("got %v, want the read-only directory reported", err)
+	}
+}
@@ -0,0 +1,117 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+)
+
+// Poller detects changed files by comparing their modification times and sizes between polls,
+// which works on every platform and filesystem
+type Poller struct {
+	list  func() ([]string, error)
+	files map[string]fileState
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewPoller polls the files returned by list, which is called on every poll so created files
+// are picked up
+func NewPoller(list func() ([]string, error)) *Poller {
+	return &Poller{list: list}
+}
+
+// Poll returns the files created, modified or removed since the last poll, sorted. The first
+// poll only records the files
+func (p *Poller) Poll() ([]string, error) {
+	paths, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	first := p.files == nil
+	var changed []string
+	for path, state := range files {
+		if old, ok := p.files[path]; !ok || !old.modTime.Equal(state.modTime) || old.size != state.size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range p.files {
+		if _, ok := files[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	p.files = files
+
+	if first {
+		return nil, nil
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// Watcher polls for changes and reports them once a burst of saves has settled
+type Watcher struct {
+	Poller   *Poller
+	Interval time.Duration // Time between polls
+	Debounce time.Duration // How long no further change must be seen before reporting
+}
+
+// Run polls until the context is done, calling onChange with every file changed during a burst.
+// An error from the poller is passed to onError and polling carries on
+func (w *Watcher) Run(ctx context.Context, onChange func([]string), onError func(error)) error {
+	if _, err := w.Poller.Poll(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	pending := make(map[string]bool)
+	var last time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			changed, err := w.Poller.Poll()
+			if err != nil {
+				onError(err)
+				continue
+			}
+			for _, path := range changed {
+				pending[path] = true
+				last = now
+			}
+
+			if len(pending) == 0 || now.Sub(last) < w.Debounce {
+				continue
+			}
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			pending = make(map[string]bool)
+			onChange(paths)
+		}
+	}
+}
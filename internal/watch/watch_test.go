@@ -0,0 +1,102 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	dir := t.TempDir()
+	a, b, c := filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go"), filepath.Join(dir, "c.go")
+	start := time.Now().Add(-time.Hour)
+	writeFile(t, a, "package a", start)
+	writeFile(t, b, "package a", start)
+
+	p := NewPoller(func() ([]string, error) { return []string{a, b, c}, nil })
+	poll := func(want ...string) {
+		t.Helper()
+		changed, err := p.Poll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(changed, want) {
+			t.Fatalf("got %v, want %v", changed, want)
+		}
+	}
+
+	poll()
+	poll()
+
+	writeFile(t, a, "package a", start.Add(time.Second))
+	poll(a)
+
+	// Same modification time, different size
+	writeFile(t, b, "package a\n", start)
+	poll(b)
+
+	writeFile(t, c, "package a", start)
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	poll(a, c)
+}
+
+func TestRunDebounces(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	start := time.Now().Add(-time.Hour)
+	writeFile(t, path, "package a", start)
+
+	w := &Watcher{
+		Poller:   NewPoller(func() ([]string, error) { return []string{path}, nil }),
+		Interval: 5 * time.Millisecond,
+		Debounce: 50 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan []string, 10)
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx, func(paths []string) { reports <- paths }, func(err error) { t.Error(err) })
+	}()
+
+	// A burst of saves, each within the debounce of the last
+	time.Sleep(20 * time.Millisecond)
+	for i := 1; i <= 5; i++ {
+		writeFile(t, path, "package a", start.Add(time.Duration(i)*time.Second))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case paths := <-reports:
+		if !reflect.DeepEqual(paths, []string{path}) {
+			t.Fatalf("got %v, want %v", paths, []string{path})
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change reported")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("burst reported %d extra times", len(reports))
+	}
+}